@@ -0,0 +1,587 @@
+// api.go
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"mismo/auth"
+	"mismo/game"
+	"mismo/ratelimit"
+	"mismo/rating"
+)
+
+// The REST API exposes the same games as the WebSocket: a change made
+// through either is broadcast to every connected player.
+//
+//	POST /api/v1/games                    create a game
+//	GET  /api/v1/games/{id}               read a game's state
+//	GET  /api/v1/games/{id}/events        follow the game as Server-Sent Events
+//	POST /api/v1/games/{id}/players       join, returning a player token
+//	POST /api/v1/games/{id}/submissions   submit a number for the round
+//	POST /api/v1/games/{id}/locks         lock in the submitted number
+//	POST /api/v1/games/{id}/cards         play a card this round
+//	POST /api/v1/games/{id}/wagers        stake a life on this round
+//	POST /api/v1/games/{id}/rounds        start the game or the next round
+//	POST /api/v1/games/{id}/teams         move a player to a team
+//	POST /api/v1/games/{id}/teams/balance deal the players out to the teams
+//	POST /api/v1/games/{id}/chat          message your team
+//	POST /api/v1/accounts                 create an account to be rated under
+//	GET  /api/v1/ratings/{account}        an account's rating and history
+//	POST /api/v1/ratings/quality          how evenly matched a table would be
+//
+// Requests and responses are JSON. Player actions authenticate with
+// "Authorization: Bearer <token>". Every error has the same envelope:
+//
+//	{"error": {"code": "not_host", "message": "only the host can do this"}}
+
+// apiError is the body of every error response.
+type apiError struct {
+	Error apiErrorBody `json:"error"`
+}
+
+type apiErrorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// engineErrors maps engine errors to their status and error code.
+var engineErrors = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{game.ErrPlayerNotFound, http.StatusNotFound, "player_not_found"},
+	{game.ErrNotHost, http.StatusForbidden, "not_host"},
+	{game.ErrEliminated, http.StatusForbidden, "eliminated"},
+	{game.ErrGameStarted, http.StatusConflict, "game_started"},
+	{game.ErrGameFull, http.StatusConflict, "game_full"},
+	{game.ErrNotEnoughPlayers, http.StatusConflict, "not_enough_players"},
+	{game.ErrNotPlaying, http.StatusConflict, "not_playing"},
+	{game.ErrRoundInProgress, http.StatusConflict, "round_in_progress"},
+	{game.ErrGameOver, http.StatusConflict, "game_over"},
+	{game.ErrNumberLocked, http.StatusConflict, "number_locked"},
+	{game.ErrNoNumber, http.StatusConflict, "no_number"},
+	{game.ErrNoTeams, http.StatusConflict, "no_teams"},
+	{game.ErrNoCards, http.StatusConflict, "no_cards"},
+	{game.ErrCardPlayed, http.StatusConflict, "card_played"},
+	{game.ErrCardNotHeld, http.StatusBadRequest, "card_not_held"},
+	{game.ErrInvalidTarget, http.StatusBadRequest, "invalid_target"},
+	{game.ErrNothingToPeek, http.StatusConflict, "nothing_to_peek"},
+	{game.ErrNoWagers, http.StatusConflict, "no_wagers"},
+	{game.ErrAlreadyWagered, http.StatusConflict, "already_wagered"},
+	{game.ErrCannotWager, http.StatusConflict, "cannot_wager"},
+	{game.ErrEmptyTeam, http.StatusConflict, "empty_team"},
+	{game.ErrInvalidTeam, http.StatusBadRequest, "invalid_team"},
+	{game.ErrEmptyName, http.StatusBadRequest, "invalid_name"},
+	{game.ErrNameTooLong, http.StatusBadRequest, "invalid_name"},
+	{game.ErrNameNotAllowed, http.StatusBadRequest, "invalid_name"},
+	{game.ErrInvalidNumber, http.StatusBadRequest, "invalid_number"},
+	{game.ErrNumberOutOfRange, http.StatusBadRequest, "number_out_of_range"},
+	{game.ErrInvalidOptions, http.StatusBadRequest, "invalid_options"},
+}
+
+// apiRoute is an HTTP endpoint. Routes are registered from apiRoutes and
+// the OpenAPI document is generated from the same table, so the two cannot
+// drift apart.
+type apiRoute struct {
+	Method  string
+	Path    string
+	Summary string
+	// Auth is set for routes that take a player token.
+	Auth bool
+	// Request and Response are zero values of the JSON bodies, or nil.
+	Request  interface{}
+	Response interface{}
+	Status   int
+	// Envelope is set for routes whose errors are apiError bodies rather
+	// than plain text. Every route under /api/ has it, since unknown paths
+	// and methods there are answered with apiError bodies too.
+	Envelope bool
+	// Stream is set for routes that send Response as Server-Sent Events.
+	Stream  bool
+	handler http.HandlerFunc
+}
+
+// apiRoutes lists the HTTP endpoints besides the static files and
+// WebSocket channels.
+func apiRoutes() []apiRoute {
+	return []apiRoute{
+		{
+			Method: http.MethodPost, Path: "/api/v1/games", Summary: "Create a game",
+			Request: createRequest{}, Response: createResponse{}, Status: http.StatusCreated, Envelope: true,
+			handler: apiLimit(createLimiter, apiCreateGame),
+		},
+		{
+			Method: http.MethodGet, Path: "/api/v1/games/{id}", Summary: "Get a game's state; a private game needs a player token or ?invite=",
+			Response: stateMessage{}, Status: http.StatusOK, Envelope: true,
+			handler: apiGetGame,
+		},
+		{
+			Method: http.MethodGet, Path: "/api/v1/games/{id}/events", Summary: "Follow the game as Server-Sent \"state\" events",
+			Auth: true, Response: stateMessage{}, Status: http.StatusOK, Envelope: true, Stream: true,
+			handler: withAPIPlayerToken(streamToken, apiEvents),
+		},
+		{
+			Method: http.MethodPost, Path: "/api/v1/games/{id}/players", Summary: "Join a game",
+			Request: joinRequest{}, Response: joinResponse{}, Status: http.StatusCreated, Envelope: true,
+			handler: apiLimit(joinLimiter, apiJoinGame),
+		},
+		{
+			Method: http.MethodPost, Path: "/api/v1/games/{id}/submissions", Summary: "Submit a number for the current round",
+			Auth: true, Request: submitRequest{}, Response: stateMessage{}, Status: http.StatusOK, Envelope: true,
+			handler: withAPIPlayer(apiSubmitNumber),
+		},
+		{
+			Method: http.MethodPost, Path: "/api/v1/games/{id}/locks", Summary: "Lock in the submitted number",
+			Auth: true, Response: stateMessage{}, Status: http.StatusOK, Envelope: true,
+			handler: withAPIPlayer(apiLockNumber),
+		},
+		{
+			Method: http.MethodPost, Path: "/api/v1/games/{id}/cards", Summary: "Play a card this round",
+			Auth: true, Request: cardRequest{}, Response: stateMessage{}, Status: http.StatusOK, Envelope: true,
+			handler: withAPIPlayer(apiPlayCard),
+		},
+		{
+			Method: http.MethodPost, Path: "/api/v1/games/{id}/wagers", Summary: "Stake a life on this round",
+			Auth: true, Response: stateMessage{}, Status: http.StatusOK, Envelope: true,
+			handler: withAPIPlayer(apiPlaceWager),
+		},
+		{
+			Method: http.MethodPost, Path: "/api/v1/games/{id}/rounds", Summary: "Start the game or the next round (host only)",
+			Auth: true, Response: stateMessage{}, Status: http.StatusOK, Envelope: true,
+			handler: withAPIPlayer(apiStartRound),
+		},
+		{
+			Method: http.MethodPost, Path: "/api/v1/games/{id}/teams", Summary: "Move a player to a team (host only)",
+			Auth: true, Request: teamRequest{}, Response: stateMessage{}, Status: http.StatusOK, Envelope: true,
+			handler: withAPIPlayer(apiAssignTeam),
+		},
+		{
+			Method: http.MethodPost, Path: "/api/v1/games/{id}/teams/balance", Summary: "Deal the players out evenly to the teams (host only)",
+			Auth: true, Response: stateMessage{}, Status: http.StatusOK, Envelope: true,
+			handler: withAPIPlayer(apiBalanceTeams),
+		},
+		{
+			Method: http.MethodPost, Path: "/api/v1/games/{id}/chat", Summary: "Send a message to your team",
+			Auth: true, Request: chatRequest{}, Status: http.StatusNoContent, Envelope: true,
+			handler: withAPIPlayer(apiChat),
+		},
+		{
+			Method: http.MethodPost, Path: "/api/v1/accounts", Summary: "Create an account to be rated under",
+			Response: accountResponse{}, Status: http.StatusCreated, Envelope: true,
+			handler: apiLimit(createLimiter, apiCreateAccount),
+		},
+		{
+			Method: http.MethodGet, Path: "/api/v1/ratings/{account}", Summary: "Get an account's rating and rating history",
+			Response: ratingResponse{}, Status: http.StatusOK, Envelope: true,
+			handler: apiGetRating,
+		},
+		{
+			Method: http.MethodPost, Path: "/api/v1/ratings/quality", Summary: "Report how evenly matched accounts would be at one table",
+			Request: qualityRequest{}, Response: rating.Quality{}, Status: http.StatusOK, Envelope: true,
+			handler: apiMatchQuality,
+		},
+		{
+			Method: http.MethodPost, Path: "/create-game", Summary: "Create a game to join over the WebSocket",
+			Request: createRequest{}, Response: createGameResponse{}, Status: http.StatusOK,
+			handler: createLimiter.Limit(createGameHandler),
+		},
+		{
+			Method: http.MethodGet, Path: "/api/lobbies", Summary: "List the public games",
+			Response: lobbiesResponse{}, Status: http.StatusOK, Envelope: true,
+			handler: lobbiesHandler,
+		},
+		{
+			Method: http.MethodGet, Path: "/api/spec", Summary: "The OpenAPI and AsyncAPI documents",
+			Response: specResponse{}, Status: http.StatusOK, Envelope: true,
+			handler: specHandler,
+		},
+	}
+}
+
+// registerAPI adds the HTTP routes to mux.
+func registerAPI(mux *http.ServeMux) {
+	var methods []string
+	for _, route := range apiRoutes() {
+		mux.HandleFunc(route.Method+" "+route.Path, route.handler)
+		if !slices.Contains(methods, route.Method) {
+			methods = append(methods, route.Method)
+			if route.Method == http.MethodGet {
+				methods = append(methods, http.MethodHead)
+			}
+		}
+	}
+	mux.HandleFunc("/api/", apiFallback(mux, methods))
+}
+
+// apiFallback answers the requests under /api/ that no route matches: 405
+// with an Allow header when the path is served with other methods, 404
+// otherwise.
+func apiFallback(mux *http.ServeMux, methods []string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var allow []string
+		for _, method := range methods {
+			probe := r.Clone(r.Context())
+			probe.Method = method
+			if _, pattern := mux.Handler(probe); pattern != "" && pattern != "/api/" {
+				allow = append(allow, method)
+			}
+		}
+		if len(allow) == 0 {
+			writeAPIError(w, http.StatusNotFound, "not_found", "no such endpoint")
+			return
+		}
+		w.Header().Set("Allow", strings.Join(allow, ", "))
+		writeAPIError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+	}
+}
+
+// rejectCrossOrigin answers state-changing requests from origins that are
+// not allowed; see auth.Origins.Protect.
+func rejectCrossOrigin(w http.ResponseWriter, r *http.Request) {
+	writeAPIError(w, http.StatusForbidden, "cross_origin", "cross-origin request rejected")
+}
+
+// createResponse is the body returned when a game is created over REST.
+type createResponse struct {
+	Game   stateMessage `json:"game"`
+	Invite string       `json:"invite,omitempty"`
+}
+
+type joinRequest struct {
+	Name     string `json:"name"`
+	Password string `json:"password,omitempty"`
+	Invite   string `json:"invite,omitempty"`
+	// Account is a token from POST /api/v1/accounts; the game is rated
+	// under it.
+	Account string `json:"account,omitempty"`
+}
+
+// joinResponse is the body returned when a player joins over REST.
+type joinResponse struct {
+	PlayerID string       `json:"playerId"`
+	Token    string       `json:"token"`
+	Game     stateMessage `json:"game"`
+}
+
+type submitRequest struct {
+	Number *game.Number `json:"number"`
+}
+
+type cardRequest struct {
+	Card   game.Card `json:"card"`
+	Target string    `json:"target,omitempty"`
+}
+
+type teamRequest struct {
+	PlayerID string `json:"playerId"`
+	Team     int    `json:"team"`
+}
+
+type chatRequest struct {
+	Text string `json:"text"`
+}
+
+func writeAPIJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeAPIError(w http.ResponseWriter, status int, code, message string) {
+	writeAPIJSON(w, status, apiError{Error: apiErrorBody{Code: code, Message: message}})
+}
+
+// writeEngineError reports an error returned by the game engine.
+func writeEngineError(w http.ResponseWriter, err error) {
+	for _, e := range engineErrors {
+		if errors.Is(err, e.err) {
+			writeAPIError(w, e.status, e.code, err.Error())
+			return
+		}
+	}
+	log.Printf("API error: %v", err)
+	writeAPIError(w, http.StatusInternalServerError, "internal", "internal error")
+}
+
+// writeRateLimited reports a throttled request.
+func writeRateLimited(w http.ResponseWriter, l *ratelimit.Limiter) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(l.RetryAfter().Seconds()))))
+	writeAPIError(w, http.StatusTooManyRequests, "rate_limited", "too many requests")
+}
+
+// apiLimit is ratelimit.Limiter.Limit with the API's error envelope.
+func apiLimit(l *ratelimit.Limiter, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !l.Allow(ratelimit.ClientIP(r)) {
+			writeRateLimited(w, l)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// decodeAPIBody reads a JSON request body into v. An empty body is allowed.
+func decodeAPIBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if errors.Is(err, game.ErrInvalidNumber) || errors.Is(err, game.ErrNumberOutOfRange) || errors.Is(err, game.ErrInvalidOptions) {
+		writeEngineError(w, err)
+		return false
+	}
+	if err != nil && err != io.EOF {
+		writeAPIError(w, http.StatusBadRequest, "invalid_body", "invalid request body")
+		return false
+	}
+	return true
+}
+
+// apiGame looks up the game named in the path.
+func apiGame(w http.ResponseWriter, r *http.Request) (*Game, bool) {
+	g, ok := findGame(r.PathValue("id"))
+	if !ok {
+		writeAPIError(w, http.StatusNotFound, "game_not_found", "game not found")
+	}
+	return g, ok
+}
+
+// apiPlayerHandler handles a request from an authenticated player.
+type apiPlayerHandler func(w http.ResponseWriter, r *http.Request, g *Game, playerID string)
+
+// withAPIPlayer authenticates the player token of a request for the game in
+// the path and limits how often each player can act.
+func withAPIPlayer(next apiPlayerHandler) http.HandlerFunc {
+	return withAPIPlayerToken(auth.BearerToken, next)
+}
+
+// withAPIPlayerToken is withAPIPlayer reading the token with the given
+// function.
+func withAPIPlayerToken(token func(*http.Request) string, next apiPlayerHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		g, ok := apiGame(w, r)
+		if !ok {
+			return
+		}
+		claims, err := signer.VerifyPlayerToken(token(r))
+		if err != nil || claims.GameID != g.ID {
+			writeAPIError(w, http.StatusUnauthorized, "unauthorized", "missing or invalid player token")
+			return
+		}
+		if !g.hasPlayer(claims.PlayerID) {
+			writeEngineError(w, game.ErrPlayerNotFound)
+			return
+		}
+		if !actionLimiter.Allow(claims.PlayerID) {
+			writeRateLimited(w, actionLimiter)
+			return
+		}
+		next(w, r, g, claims.PlayerID)
+	}
+}
+
+// apiCreateGame creates a game. The body takes the same fields as
+// /create-game; the response is the game's state and, for private games,
+// an invite token.
+func apiCreateGame(w http.ResponseWriter, r *http.Request) {
+	var req createRequest
+	if !decodeAPIBody(w, r, &req) {
+		return
+	}
+
+	g, invite, err := req.create()
+	switch {
+	case err == errPublicPrivate || errors.Is(err, game.ErrInvalidOptions):
+		writeAPIError(w, http.StatusBadRequest, "invalid_options", err.Error())
+		return
+	case err == errServerFull:
+		writeAPIError(w, http.StatusServiceUnavailable, "server_full", err.Error())
+		return
+	case err != nil:
+		log.Printf("Error creating game: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "internal", "failed to create game")
+		return
+	}
+
+	writeAPIJSON(w, http.StatusCreated, createResponse{Game: g.state(""), Invite: invite})
+}
+
+// apiGetGame serves a game's state, as seen by the player whose token the
+// request carries, if any. A private game is only shown to its players and
+// to holders of an invite, passed as ?invite=.
+func apiGetGame(w http.ResponseWriter, r *http.Request) {
+	g, ok := apiGame(w, r)
+	if !ok {
+		return
+	}
+	viewer := ""
+	if token := auth.BearerToken(r); token != "" {
+		claims, err := signer.VerifyPlayerToken(token)
+		if err != nil || claims.GameID != g.ID || !g.hasPlayer(claims.PlayerID) {
+			writeAPIError(w, http.StatusUnauthorized, "unauthorized", "missing or invalid player token")
+			return
+		}
+		viewer = claims.PlayerID
+	} else if g.Options.Private {
+		if err := g.checkAccess("", r.URL.Query().Get("invite")); err != nil {
+			writeAPIError(w, http.StatusUnauthorized, "unauthorized", "a private game needs a player token or an invite")
+			return
+		}
+	}
+	writeAPIJSON(w, http.StatusOK, g.state(viewer))
+}
+
+// apiJoinGame adds a player, taking {"name", "password", "invite"}. The
+// returned token authenticates the player's later requests and can attach a
+// WebSocket to the player with {"type": "join", "token": ...}.
+func apiJoinGame(w http.ResponseWriter, r *http.Request) {
+	g, ok := apiGame(w, r)
+	if !ok {
+		return
+	}
+	var req joinRequest
+	if !decodeAPIBody(w, r, &req) {
+		return
+	}
+	if err := g.checkAccess(req.Password, req.Invite); err != nil {
+		writeAPIError(w, http.StatusForbidden, "access_denied", err.Error())
+		return
+	}
+
+	account, err := verifyAccount(req.Account)
+	if err != nil {
+		writeAPIError(w, http.StatusUnauthorized, "invalid_account", "invalid account token")
+		return
+	}
+
+	playerID, err := g.addPlayer(req.Name, account, nil)
+	if err == errAccountInGame {
+		writeAPIError(w, http.StatusConflict, "account_in_game", "account already has a seat in this game")
+		return
+	}
+	if err != nil {
+		writeEngineError(w, err)
+		return
+	}
+	token, err := signer.NewPlayerToken(g.ID, playerID, tokenTTL)
+	if err != nil {
+		writeEngineError(w, err)
+		return
+	}
+	auth.SetSessionCookie(w, r, token, tokenTTL)
+	g.broadcast()
+	writeAPIJSON(w, http.StatusCreated, joinResponse{PlayerID: playerID, Token: token, Game: g.state(playerID)})
+}
+
+// apiSubmitNumber submits {"number"} for the current round.
+func apiSubmitNumber(w http.ResponseWriter, r *http.Request, g *Game, playerID string) {
+	var req submitRequest
+	if !decodeAPIBody(w, r, &req) {
+		return
+	}
+	if req.Number == nil {
+		writeEngineError(w, game.ErrInvalidNumber)
+		return
+	}
+	if err := g.engine.SubmitNumber(playerID, *req.Number); err != nil {
+		writeEngineError(w, err)
+		return
+	}
+	g.broadcast()
+	writeAPIJSON(w, http.StatusOK, g.state(playerID))
+}
+
+// apiLockNumber locks the player's number for the current round.
+func apiLockNumber(w http.ResponseWriter, r *http.Request, g *Game, playerID string) {
+	if err := g.engine.LockNumber(playerID); err != nil {
+		writeEngineError(w, err)
+		return
+	}
+	g.broadcast()
+	writeAPIJSON(w, http.StatusOK, g.state(playerID))
+}
+
+// apiPlayCard plays {"card"} at an optional {"target"}.
+func apiPlayCard(w http.ResponseWriter, r *http.Request, g *Game, playerID string) {
+	var req cardRequest
+	if !decodeAPIBody(w, r, &req) {
+		return
+	}
+	if err := g.engine.PlayCard(playerID, req.Card, req.Target); err != nil {
+		writeEngineError(w, err)
+		return
+	}
+	g.broadcast()
+	writeAPIJSON(w, http.StatusOK, g.state(playerID))
+}
+
+func apiPlaceWager(w http.ResponseWriter, r *http.Request, g *Game, playerID string) {
+	if err := g.engine.PlaceWager(playerID); err != nil {
+		writeEngineError(w, err)
+		return
+	}
+	g.broadcast()
+	writeAPIJSON(w, http.StatusOK, g.state(playerID))
+}
+
+// apiStartRound starts the game if it is waiting, and otherwise the next
+// round. Only the host can do either.
+func apiStartRound(w http.ResponseWriter, r *http.Request, g *Game, playerID string) {
+	var err error
+	if g.engine.Snapshot().State == game.Waiting {
+		err = g.engine.Start(playerID)
+	} else {
+		err = g.engine.NextRound(playerID)
+	}
+	if err != nil {
+		writeEngineError(w, err)
+		return
+	}
+	g.broadcast()
+	writeAPIJSON(w, http.StatusOK, g.state(playerID))
+}
+
+// apiAssignTeam moves {"playerId"} to {"team"} before the game starts.
+func apiAssignTeam(w http.ResponseWriter, r *http.Request, g *Game, playerID string) {
+	var req teamRequest
+	if !decodeAPIBody(w, r, &req) {
+		return
+	}
+	if err := g.engine.AssignTeam(playerID, req.PlayerID, req.Team); err != nil {
+		writeEngineError(w, err)
+		return
+	}
+	g.broadcast()
+	writeAPIJSON(w, http.StatusOK, g.state(playerID))
+}
+
+func apiBalanceTeams(w http.ResponseWriter, r *http.Request, g *Game, playerID string) {
+	if err := g.engine.BalanceTeams(playerID); err != nil {
+		writeEngineError(w, err)
+		return
+	}
+	g.broadcast()
+	writeAPIJSON(w, http.StatusOK, g.state(playerID))
+}
+
+// apiChat sends {"text"} to the player's connected teammates.
+func apiChat(w http.ResponseWriter, r *http.Request, g *Game, playerID string) {
+	var req chatRequest
+	if !decodeAPIBody(w, r, &req) {
+		return
+	}
+	switch err := g.chat(playerID, req.Text); err {
+	case nil:
+		w.WriteHeader(http.StatusNoContent)
+	case errNoTeamChat:
+		writeAPIError(w, http.StatusConflict, "no_teams", err.Error())
+	default:
+		writeAPIError(w, http.StatusBadRequest, "invalid_chat", err.Error())
+	}
+}
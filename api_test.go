@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"mismo/auth"
+	"mismo/game"
+)
+
+func TestGetGameAccess(t *testing.T) {
+	server := testServer(t)
+	public := testGame(t, defaultOptions())
+	opts := defaultOptions()
+	opts.Private = true
+	private := testGame(t, opts)
+	invite, _, err := signer.NewInvite(private.ID, inviteTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	player := joinAPI(t, server, private.ID, joinRequest{Name: "ana", Invite: invite})
+	stranger := joinAPI(t, server, public.ID, joinRequest{Name: "bea"})
+
+	tests := []struct {
+		name   string
+		game   *Game
+		query  string
+		token  string
+		status int
+	}{
+		{"public game", public, "", "", http.StatusOK},
+		{"public game as a player", public, "", stranger.Token, http.StatusOK},
+		{"private game", private, "", "", http.StatusUnauthorized},
+		{"private game with a bad invite", private, "?invite=nope", "", http.StatusUnauthorized},
+		{"private game with an invite", private, "?invite=" + invite, "", http.StatusOK},
+		{"private game as a player", private, "", player.Token, http.StatusOK},
+		{"private game with another game's token", private, "", stranger.Token, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, server.URL+"/api/v1/games/"+tt.game.ID+tt.query, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+			if tt.status != http.StatusOK {
+				var body apiError
+				if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body.Error.Code != "unauthorized" {
+					t.Errorf("error body = %+v, %v, want an unauthorized envelope", body, err)
+				}
+				return
+			}
+			var state stateMessage
+			if err := json.NewDecoder(resp.Body).Decode(&state); err != nil || state.ID != tt.game.ID {
+				t.Errorf("state = %+v, %v, want game %s", state, err, tt.game.ID)
+			}
+		})
+	}
+}
+
+func TestAPIErrorsUseEnvelope(t *testing.T) {
+	mux := http.NewServeMux()
+	registerAPI(mux)
+	origins := auth.ParseOrigins("")
+	origins.Reject = rejectCrossOrigin
+	handler := origins.Protect(mux)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		origin string
+		status int
+		code   string
+		allow  string
+	}{
+		{"unknown path", http.MethodGet, "/api/v1/nope", "", http.StatusNotFound, "not_found", ""},
+		{"unknown game path", http.MethodGet, "/api/v1/games/ABC123/nope", "", http.StatusNotFound, "not_found", ""},
+		{"wrong method", http.MethodDelete, "/api/v1/games/ABC123", "", http.StatusMethodNotAllowed, "method_not_allowed", "GET, HEAD"},
+		{"GET on a POST route", http.MethodGet, "/api/v1/games/ABC123/players", "", http.StatusMethodNotAllowed, "method_not_allowed", "POST"},
+		{"POST on the spec", http.MethodPost, "/api/spec", "", http.StatusMethodNotAllowed, "method_not_allowed", "GET, HEAD"},
+		{"cross-origin POST", http.MethodPost, "/api/v1/games", "https://evil.example", http.StatusForbidden, "cross_origin", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "http://mismo.test"+tt.path, nil)
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d", w.Code, tt.status)
+			}
+			if got := w.Header().Get("Allow"); got != tt.allow {
+				t.Errorf("Allow = %q, want %q", got, tt.allow)
+			}
+			var body apiError
+			if err := json.NewDecoder(w.Body).Decode(&body); err != nil || body.Error.Code != tt.code {
+				t.Errorf("error body = %+v, %v, want code %q", body, err, tt.code)
+			}
+		})
+	}
+}
+
+func TestCreateGameDefaultsToClassicTies(t *testing.T) {
+	server := testServer(t)
+	resp, err := http.Post(server.URL+"/api/v1/games", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var created createResponse
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { unregisterGame(created.Game.ID) })
+	if got := created.Game.Options.Ties; got != game.ClassicTieRules {
+		t.Errorf("ties = %+v, want the classic rules %+v", got, game.ClassicTieRules)
+	}
+}
@@ -0,0 +1,247 @@
+// Package auth provides the credentials used to guard games: join password
+// hashes and HMAC-signed tokens such as invite links.
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrExpiredToken = errors.New("token has expired")
+)
+
+// Signer signs and verifies tokens with a server secret.
+type Signer struct {
+	key []byte
+}
+
+func NewSigner(key []byte) *Signer {
+	return &Signer{key: key}
+}
+
+// NewSignerFromEnv uses the MISMO_SECRET environment variable as the key, or
+// a random key when it is unset, in which case tokens do not survive a
+// restart (neither do games).
+func NewSignerFromEnv() (*Signer, error) {
+	if secret := os.Getenv("MISMO_SECRET"); secret != "" {
+		return NewSigner([]byte(secret)), nil
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return NewSigner(key), nil
+}
+
+func (s *Signer) mac(payload string) []byte {
+	m := hmac.New(sha256.New, s.key)
+	m.Write([]byte(payload))
+	return m.Sum(nil)
+}
+
+// sign encodes claims as a token of the form payload.signature.
+func (s *Signer) sign(claims interface{}) (string, error) {
+	data, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(s.mac(payload)), nil
+}
+
+// verify checks the signature of a token and decodes its claims.
+func (s *Signer) verify(token string, claims interface{}) error {
+	payload, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return ErrInvalidToken
+	}
+	got, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(got, s.mac(payload)) {
+		return ErrInvalidToken
+	}
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return ErrInvalidToken
+	}
+	if err := json.Unmarshal(data, claims); err != nil {
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+// invite are the claims of an invite token.
+type invite struct {
+	Kind    string `json:"k"`
+	GameID  string `json:"g"`
+	Expires int64  `json:"exp"`
+}
+
+// NewInvite returns a token that lets its bearer join the game until ttl
+// has elapsed.
+func (s *Signer) NewInvite(gameID string, ttl time.Duration) (string, time.Time, error) {
+	expires := time.Now().Add(ttl)
+	token, err := s.sign(invite{Kind: "invite", GameID: gameID, Expires: expires.Unix()})
+	return token, expires, err
+}
+
+// VerifyInvite checks that token is a valid, unexpired invite to the game.
+func (s *Signer) VerifyInvite(token, gameID string) error {
+	var claims invite
+	if err := s.verify(token, &claims); err != nil {
+		return err
+	}
+	if claims.Kind != "invite" || claims.GameID != gameID {
+		return ErrInvalidToken
+	}
+	if time.Now().Unix() > claims.Expires {
+		return ErrExpiredToken
+	}
+	return nil
+}
+
+// player are the claims of a player token.
+type player struct {
+	Kind     string `json:"k"`
+	GameID   string `json:"g"`
+	PlayerID string `json:"p"`
+	Expires  int64  `json:"exp"`
+}
+
+// PlayerClaims identify the player a token was issued to.
+type PlayerClaims struct {
+	GameID   string
+	PlayerID string
+}
+
+// NewPlayerToken returns a bearer token bound to one player of one game.
+func (s *Signer) NewPlayerToken(gameID, playerID string, ttl time.Duration) (string, error) {
+	return s.sign(player{
+		Kind:     "player",
+		GameID:   gameID,
+		PlayerID: playerID,
+		Expires:  time.Now().Add(ttl).Unix(),
+	})
+}
+
+// VerifyPlayerToken checks a token from NewPlayerToken and returns the
+// player it is bound to.
+func (s *Signer) VerifyPlayerToken(token string) (PlayerClaims, error) {
+	var claims player
+	if err := s.verify(token, &claims); err != nil {
+		return PlayerClaims{}, err
+	}
+	if claims.Kind != "player" {
+		return PlayerClaims{}, ErrInvalidToken
+	}
+	if time.Now().Unix() > claims.Expires {
+		return PlayerClaims{}, ErrExpiredToken
+	}
+	return PlayerClaims{GameID: claims.GameID, PlayerID: claims.PlayerID}, nil
+}
+
+// account are the claims of an account token.
+type account struct {
+	Kind    string `json:"k"`
+	Account string `json:"a"`
+	Expires int64  `json:"exp"`
+}
+
+// NewAccountToken returns a bearer token proving ownership of an account,
+// the identity finished games are rated under.
+func (s *Signer) NewAccountToken(accountID string, ttl time.Duration) (string, error) {
+	return s.sign(account{Kind: "account", Account: accountID, Expires: time.Now().Add(ttl).Unix()})
+}
+
+// VerifyAccountToken checks a token from NewAccountToken and returns the
+// account it proves.
+func (s *Signer) VerifyAccountToken(token string) (string, error) {
+	var claims account
+	if err := s.verify(token, &claims); err != nil {
+		return "", err
+	}
+	if claims.Kind != "account" || claims.Account == "" {
+		return "", ErrInvalidToken
+	}
+	if time.Now().Unix() > claims.Expires {
+		return "", ErrExpiredToken
+	}
+	return claims.Account, nil
+}
+
+// BearerToken returns the token from an "Authorization: Bearer" header.
+func BearerToken(r *http.Request) string {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// Join passwords are hashed with argon2id at the parameters OWASP
+// recommends, and encoded in the PHC string format,
+//
+//	$argon2id$v=19$m=19456,t=2,p=1$<salt>$<key>
+//
+// so that the parameters can be raised later without breaking the hashes
+// already issued.
+const (
+	argonMemory  = 19 * 1024 // KiB
+	argonTime    = 2
+	argonThreads = 1
+	argonKeyLen  = 32
+	argonSaltLen = 16
+)
+
+// HashPassword returns a salted hash of a join password.
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, argonSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, argonTime, argonMemory, argonThreads, argonKeyLen)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, argonMemory, argonTime, argonThreads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// CheckPassword reports whether password matches a hash from HashPassword,
+// using the parameters recorded in the hash.
+func CheckPassword(hash, password string) bool {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != "argon2id" || parts[2] != fmt.Sprintf("v=%d", argon2.Version) {
+		return false
+	}
+	var memory, passes uint32
+	var threads uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &passes, &threads); err != nil {
+		return false
+	}
+	// Only this server issues hashes; the caps bound the work a corrupted
+	// one could ask for.
+	if memory == 0 || memory > 4*argonMemory || passes == 0 || passes > 4*argonTime || threads == 0 {
+		return false
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false
+	}
+	want, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(want) == 0 {
+		return false
+	}
+	got := argon2.IDKey([]byte(password), salt, passes, memory, threads, uint32(len(want)))
+	return subtle.ConstantTimeCompare(got, want) == 1
+}
@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckPassword(t *testing.T) {
+	hash, err := HashPassword("hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=19456,t=2,p=1$") {
+		t.Fatalf("hash = %q, want an argon2id PHC string", hash)
+	}
+	if again, _ := HashPassword("hunter2"); again == hash {
+		t.Error("two hashes of a password share a salt")
+	}
+	parts := strings.Split(hash, "$")
+
+	tests := []struct {
+		name     string
+		hash     string
+		password string
+		want     bool
+	}{
+		{"right password", hash, "hunter2", true},
+		{"wrong password", hash, "hunter3", false},
+		{"empty password", hash, "", false},
+		{"other algorithm", strings.Replace(hash, "argon2id", "argon2i", 1), "hunter2", false},
+		{"other version", strings.Replace(hash, "v=19", "v=16", 1), "hunter2", false},
+		{"other parameters", strings.Replace(hash, "t=2", "t=3", 1), "hunter2", false},
+		{"excessive memory", strings.Replace(hash, "m=19456", "m=4194304", 1), "hunter2", false},
+		{"zero passes", strings.Replace(hash, "t=2", "t=0", 1), "hunter2", false},
+		{"other salt", strings.Join(append(parts[:4:4], "c2FsdHNhbHRzYWx0c2FsdA", parts[5]), "$"), "hunter2", false},
+		{"missing key", strings.Join(parts[:5], "$"), "hunter2", false},
+		{"legacy salted SHA-256", "c2FsdHNhbHRzYWx0c2FsdA$47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU", "", false},
+		{"empty hash", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CheckPassword(tt.hash, tt.password); got != tt.want {
+				t.Errorf("CheckPassword = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAccountToken(t *testing.T) {
+	s := NewSigner([]byte("secret"))
+	token, err := s.NewAccountToken("acct-1", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired, _ := s.NewAccountToken("acct-1", -time.Hour)
+	player, _ := s.NewPlayerToken("GAME", "acct-1", time.Hour)
+	other, _ := NewSigner([]byte("other")).NewAccountToken("acct-1", time.Hour)
+
+	tests := []struct {
+		name  string
+		token string
+		want  string
+		err   error
+	}{
+		{"valid", token, "acct-1", nil},
+		{"expired", expired, "", ErrExpiredToken},
+		{"player token", player, "", ErrInvalidToken},
+		{"other signer", other, "", ErrInvalidToken},
+		{"garbage", "nope", "", ErrInvalidToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.VerifyAccountToken(tt.token)
+			if got != tt.want || err != tt.err {
+				t.Errorf("VerifyAccountToken = %q, %v, want %q, %v", got, err, tt.want, tt.err)
+			}
+		})
+	}
+}
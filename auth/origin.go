@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// SessionCookie holds a player token for browsers, so that the page does not
+// have to keep it in script-readable storage.
+const SessionCookie = "mismo_session"
+
+// Origins is the set of foreign origins allowed to open WebSockets and make
+// state-changing requests. Same-origin requests are always allowed.
+type Origins struct {
+	// Reject writes the response to a rejected request; by default it is a
+	// plain-text 403.
+	Reject http.HandlerFunc
+
+	allowed map[string]bool
+	any     bool
+}
+
+// ParseOrigins reads a comma-separated list such as
+// "https://mismo.example,http://localhost:5173". "*" allows every origin.
+func ParseOrigins(list string) Origins {
+	o := Origins{allowed: make(map[string]bool)}
+	for _, origin := range strings.Split(list, ",") {
+		origin = strings.TrimSpace(origin)
+		if origin == "*" {
+			o.any = true
+		} else if origin != "" {
+			o.allowed[strings.ToLower(strings.TrimSuffix(origin, "/"))] = true
+		}
+	}
+	return o
+}
+
+// OriginsFromEnv reads the MISMO_ALLOWED_ORIGINS environment variable.
+func OriginsFromEnv() Origins {
+	return ParseOrigins(os.Getenv("MISMO_ALLOWED_ORIGINS"))
+}
+
+// Check reports whether the request comes from the server's own origin or an
+// allowed one. Requests without an Origin header come from non-browser
+// clients, unless the browser marks them as cross-site.
+func (o Origins) Check(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return r.Header.Get("Sec-Fetch-Site") != "cross-site"
+	}
+	if o.any {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	return o.allowed[strings.ToLower(origin)]
+}
+
+// Protect rejects state-changing requests from origins that are not allowed.
+// Safe methods pass through; WebSocket upgrades are checked by the upgrader.
+func (o Origins) Protect(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			if !o.Check(r) {
+				if o.Reject != nil {
+					o.Reject(w, r)
+				} else {
+					http.Error(w, "Cross-origin request rejected", http.StatusForbidden)
+				}
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// SetSessionCookie stores a player token in a SameSite cookie.
+func SetSessionCookie(w http.ResponseWriter, r *http.Request, token string, ttl time.Duration) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookie,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   int(ttl.Seconds()),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// RequestToken returns the bearer token of a request, falling back to the
+// session cookie.
+func RequestToken(r *http.Request) string {
+	if token := BearerToken(r); token != "" {
+		return token
+	}
+	if c, err := r.Cookie(SessionCookie); err == nil {
+		return c.Value
+	}
+	return ""
+}
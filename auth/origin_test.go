@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestProtect(t *testing.T) {
+	origins := ParseOrigins("https://allowed.example")
+	handler := origins.Protect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		name    string
+		method  string
+		headers map[string]string
+		want    int
+	}{
+		{"foreign origin POST", http.MethodPost, map[string]string{"Origin": "https://evil.example"}, http.StatusForbidden},
+		{"allowed origin POST", http.MethodPost, map[string]string{"Origin": "https://allowed.example"}, http.StatusNoContent},
+		{"allowed origin with other case", http.MethodPost, map[string]string{"Origin": "HTTPS://Allowed.Example"}, http.StatusNoContent},
+		{"same origin POST", http.MethodPost, map[string]string{"Origin": "http://mismo.test"}, http.StatusNoContent},
+		{"cross-site POST without origin", http.MethodPost, map[string]string{"Sec-Fetch-Site": "cross-site"}, http.StatusForbidden},
+		{"same-site POST without origin", http.MethodPost, map[string]string{"Sec-Fetch-Site": "same-origin"}, http.StatusNoContent},
+		{"non-browser POST", http.MethodPost, nil, http.StatusNoContent},
+		{"malformed origin POST", http.MethodPost, map[string]string{"Origin": "null"}, http.StatusForbidden},
+		{"foreign origin GET", http.MethodGet, map[string]string{"Origin": "https://evil.example"}, http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "http://mismo.test/api/v1/games", nil)
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebSocketOrigin(t *testing.T) {
+	origins := ParseOrigins("https://allowed.example")
+	upgrader := websocket.Upgrader{CheckOrigin: origins.Check}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		conn.Close()
+	}))
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	tests := []struct {
+		name    string
+		headers http.Header
+		ok      bool
+	}{
+		{"foreign origin", http.Header{"Origin": {"https://evil.example"}}, false},
+		{"allowed origin", http.Header{"Origin": {"https://allowed.example"}}, true},
+		{"same origin", http.Header{"Origin": {server.URL}}, true},
+		{"cross-site without origin", http.Header{"Sec-Fetch-Site": {"cross-site"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, resp, err := websocket.DefaultDialer.Dial(url, tt.headers)
+			if conn != nil {
+				conn.Close()
+			}
+			if ok := err == nil; ok != tt.ok {
+				t.Fatalf("upgrade succeeded = %v, want %v (err %v)", ok, tt.ok, err)
+			}
+			if !tt.ok && resp.StatusCode != http.StatusForbidden {
+				t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusForbidden)
+			}
+		})
+	}
+}
+
+func TestProtectReject(t *testing.T) {
+	origins := ParseOrigins("")
+	origins.Reject = func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}
+	handler := origins.Protect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	r := httptest.NewRequest(http.MethodPost, "http://mismo.test/api/v1/games", nil)
+	r.Header.Set("Origin", "https://evil.example")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusTeapot {
+		t.Errorf("status = %d, want the Reject handler's %d", w.Code, http.StatusTeapot)
+	}
+}
+
+func TestParseOriginsAny(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "http://mismo.test/", nil)
+	r.Header.Set("Origin", "https://anywhere.example")
+	if !ParseOrigins("*").Check(r) {
+		t.Error(`"*" should allow every origin`)
+	}
+	if ParseOrigins("").Check(r) {
+		t.Error("an empty list should only allow the server's own origin")
+	}
+}
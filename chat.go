@@ -0,0 +1,74 @@
+// chat.go
+package main
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// In team games players can chat with their teammates. Messages are not
+// stored: a message reaches the teammates who are connected when it is sent,
+// including the sender's own connection.
+
+// maxChatLength caps a chat message, in characters.
+const maxChatLength = 280
+
+var (
+	errNoTeamChat  = errors.New("Chat is only available to teammates in team games.")
+	errEmptyChat   = errors.New("Message is empty.")
+	errChatTooLong = errors.New("Message is too long.")
+)
+
+type chatRequestMessage struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// chatMessage is a chat message as delivered to teammates.
+type chatMessage struct {
+	Type     string    `json:"type"`
+	PlayerID string    `json:"playerId"`
+	Name     string    `json:"name"`
+	Team     int       `json:"team"`
+	Text     string    `json:"text"`
+	SentAt   time.Time `json:"sentAt"`
+}
+
+// chat sends a message from a player to their team.
+func (g *Game) chat(playerID, text string) error {
+	text = strings.TrimSpace(text)
+	switch {
+	case text == "":
+		return errEmptyChat
+	case utf8.RuneCountInString(text) > maxChatLength:
+		return errChatTooLong
+	}
+
+	teams := make(map[string]int)
+	msg := chatMessage{Type: "chat", PlayerID: playerID, Text: text, SentAt: time.Now()}
+	for _, p := range g.engine.Snapshot().Players {
+		teams[p.ID] = p.Team
+		if p.ID == playerID {
+			msg.Name, msg.Team = p.Name, p.Team
+		}
+	}
+	if msg.Team == 0 {
+		return errNoTeamChat
+	}
+
+	g.mu.Lock()
+	var teammates []*client
+	for id, c := range g.clients {
+		if teams[id] == msg.Team {
+			teammates = append(teammates, c)
+		}
+	}
+	g.mu.Unlock()
+
+	for _, c := range teammates {
+		c.send(msg)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+// escapeAll writes s as a JSON string with every character escaped, the
+// longest encoding a client may send.
+func escapeAll(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		if r > 0xFFFF {
+			r -= 0x10000
+			fmt.Fprintf(&b, `\u%04x\u%04x`, 0xD800+(r>>10), 0xDC00+(r&0x3FF))
+			continue
+		}
+		fmt.Fprintf(&b, `\u%04x`, r)
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func TestChatFitsMessageSize(t *testing.T) {
+	server := testServer(t)
+	opts := defaultOptions()
+	opts.Teams = 2
+	g := testGame(t, opts)
+	conn := dialGame(t, server, g.ID)
+	if err := conn.WriteJSON(joinMessage{Type: "join", Name: "ana"}); err != nil {
+		t.Fatal(err)
+	}
+	var joined joinedMessage
+	json.Unmarshal(readMessage(t, conn, "joined"), &joined)
+
+	longest := strings.Repeat("😀", maxChatLength)
+	tests := []struct {
+		name  string
+		frame string
+	}{
+		{"ASCII", `{"type":"chat","text":"` + strings.Repeat("a", maxChatLength) + `"}`},
+		{"four-byte characters", `{"type":"chat","text":"` + longest + `"}`},
+		{"escaped four-byte characters", `{"type":"chat","text":` + escapeAll(longest) + `}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if len(tt.frame) > maxMessageSize {
+				t.Fatalf("a %d-character chat takes %d bytes, over maxMessageSize", maxChatLength, len(tt.frame))
+			}
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(tt.frame)); err != nil {
+				t.Fatal(err)
+			}
+			var msg chatMessage
+			if err := json.Unmarshal(readMessage(t, conn, "chat"), &msg); err != nil {
+				t.Fatal(err)
+			}
+			var sent chatRequestMessage
+			json.Unmarshal([]byte(tt.frame), &sent)
+			if msg.Text != sent.Text {
+				t.Errorf("chat text = %q, want %q", msg.Text, sent.Text)
+			}
+		})
+	}
+
+	if !g.hasPlayer(joined.PlayerID) {
+		t.Error("player was removed from the game")
+	}
+}
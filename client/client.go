@@ -0,0 +1,301 @@
+// Package client talks to a Mismo server for bots, load tests and terminal
+// clients.
+//
+// Games are created, joined and played through the REST API, which returns
+// errors synchronously; a Session then follows the game over a WebSocket,
+// delivering each state update on a channel and reconnecting when the
+// connection drops.
+//
+//	c := client.New("http://localhost:8080")
+//	g, err := c.CreateGame(ctx, client.CreateOptions{})
+//	session, err := c.Join(ctx, g.ID, client.JoinOptions{Name: "bot"})
+//	defer session.Close()
+//	for state := range session.States() {
+//		...
+//		_, err := session.Submit(ctx, game.NewNumber(42))
+//	}
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"mismo/game"
+
+	"github.com/gorilla/websocket"
+)
+
+// APIError is an error returned by the server.
+type APIError struct {
+	Status  int
+	Code    string // for example "not_host" or "game_over"
+	Message string
+	// RetryAfter is set when the request was rate limited.
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("mismo: %s (%d %s)", e.Message, e.Status, e.Code)
+}
+
+// Options are the settings of a game.
+type Options struct {
+	game.Options
+	Public  bool `json:"public"`
+	Private bool `json:"private"`
+}
+
+// Player is a player as seen in a State. Other players' numbers are only
+// set once a round is evaluated, and then as the game's reveal policy
+// allows.
+type Player struct {
+	ID              string       `json:"id"`
+	Name            string       `json:"name"`
+	Lives           int          `json:"lives"`
+	Number          *game.Number `json:"number"`
+	HasPlayed       bool         `json:"hasPlayed"`
+	Locked          bool         `json:"locked"`
+	Wagered         bool         `json:"wagered,omitempty"`
+	IsHost          bool         `json:"isHost"`
+	Team            int          `json:"team,omitempty"`
+	EliminatedRound int          `json:"eliminatedRound,omitempty"`
+	// CardCount is the size of the player's hand. Cards and Play are only
+	// set for the session's own player.
+	CardCount int            `json:"cardCount,omitempty"`
+	Cards     []game.Card    `json:"cards,omitempty"`
+	Play      *game.CardPlay `json:"play,omitempty"`
+}
+
+// ChatMessage is a message from a teammate.
+type ChatMessage struct {
+	PlayerID string    `json:"playerId"`
+	Name     string    `json:"name"`
+	Team     int       `json:"team"`
+	Text     string    `json:"text"`
+	SentAt   time.Time `json:"sentAt"`
+}
+
+// State is a game's state.
+type State struct {
+	ID        string            `json:"id"`
+	State     game.GameState    `json:"state"`
+	Phase     game.Phase        `json:"phase"`
+	Round     int               `json:"round"`
+	Options   Options           `json:"options"`
+	Range     game.Range        `json:"range"`
+	Players   map[string]Player `json:"players"`
+	LastRound *game.RoundResult `json:"lastRound,omitempty"`
+	// Deadline is when the current round times out, if it does.
+	Deadline *time.Time `json:"deadline,omitempty"`
+	// Standings are set once the game is finished.
+	Standings *game.Standings `json:"standings,omitempty"`
+}
+
+// CreateOptions configure a new game. Zero values use the server defaults.
+type CreateOptions struct {
+	Public     bool         `json:"public,omitempty"`
+	Private    bool         `json:"private,omitempty"`
+	Password   string       `json:"password,omitempty"`
+	Lives      int          `json:"lives,omitempty"`
+	MinPlayers int          `json:"minPlayers,omitempty"`
+	MinNumber  *game.Number `json:"minNumber,omitempty"`
+	MaxNumber  *game.Number `json:"maxNumber,omitempty"`
+	// Mode picks the round rule, for example game.ModeLowestUnique.
+	Mode            game.Mode `json:"mode,omitempty"`
+	WinnerGainsLife bool      `json:"winnerGainsLife,omitempty"`
+	// BeautyFactor is the p of game.ModeBeautyContest, 2/3 by default.
+	BeautyFactor *game.Fraction `json:"beautyFactor,omitempty"`
+	// Teams splits the players into this many teams sharing their lives.
+	Teams int `json:"teams,omitempty"`
+	// CardEvery deals every player a card every CardEvery rounds.
+	CardEvery int `json:"cardEvery,omitempty"`
+	// Reveal limits what players see of each round's numbers, for
+	// example game.RevealAnonymous.
+	Reveal game.Reveal `json:"reveal,omitempty"`
+	// Wagers and MedianBonus enable life wagers and the median bonus.
+	Wagers      bool `json:"wagers,omitempty"`
+	MedianBonus bool `json:"medianBonus,omitempty"`
+	// Endgame applies once EndgamePlayers (default 2) players are left.
+	Endgame        game.Endgame `json:"endgame,omitempty"`
+	EndgamePlayers int          `json:"endgamePlayers,omitempty"`
+	// Ties replace the server's default tie rules, game.ClassicTieRules;
+	// for example game.DefaultTieRules.
+	Ties *game.TieRules `json:"ties,omitempty"`
+	// LockIn lets players change their number until they Lock it.
+	LockIn bool `json:"lockIn,omitempty"`
+	// RoundSeconds ends each round after that many seconds, at most an
+	// hour; players who have not submitted a number by then lose a life.
+	RoundSeconds int `json:"roundSeconds,omitempty"`
+	// ShrinkWidths narrow the allowed range round by round; see
+	// game.Options.
+	ShrinkWidths   []game.Number `json:"shrinkWidths,omitempty"`
+	ShrinkToMedian bool          `json:"shrinkToMedian,omitempty"`
+}
+
+// JoinOptions identify a player joining a game. Private games need the
+// password or an invite.
+type JoinOptions struct {
+	Name     string `json:"name"`
+	Password string `json:"password,omitempty"`
+	Invite   string `json:"invite,omitempty"`
+	// Account is the token of an account from CreateAccount; the game is
+	// rated under it. Players without one are not rated.
+	Account string `json:"account,omitempty"`
+}
+
+// Account is an identity that finished games are rated under.
+type Account struct {
+	ID string `json:"account"`
+	// Token proves ownership of the account and cannot be recovered.
+	Token string `json:"token"`
+}
+
+// Game is a created game.
+type Game struct {
+	ID    string
+	State State
+	// Invite lets players join a private game without its password.
+	Invite string
+}
+
+// Client is a connection to a server. Its fields may be changed before
+// first use.
+type Client struct {
+	// BaseURL is the server address, such as "http://localhost:8080".
+	BaseURL    string
+	HTTPClient *http.Client
+	Dialer     *websocket.Dialer
+	// MaxBackoff caps the wait between reconnection attempts.
+	MaxBackoff time.Duration
+}
+
+func New(baseURL string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		HTTPClient: http.DefaultClient,
+		Dialer:     websocket.DefaultDialer,
+		MaxBackoff: 30 * time.Second,
+	}
+}
+
+// CreateGame creates a game. The caller still has to join it; the first
+// player to join becomes the host.
+func (c *Client) CreateGame(ctx context.Context, opts CreateOptions) (*Game, error) {
+	var resp struct {
+		Game   State  `json:"game"`
+		Invite string `json:"invite"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/games", "", opts, &resp); err != nil {
+		return nil, err
+	}
+	return &Game{ID: resp.Game.ID, State: resp.Game, Invite: resp.Invite}, nil
+}
+
+// CreateAccount creates an account to be rated under.
+func (c *Client) CreateAccount(ctx context.Context) (*Account, error) {
+	var account Account
+	if err := c.do(ctx, http.MethodPost, "/api/v1/accounts", "", nil, &account); err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// GameState fetches a game's state. A private game is only shown to its
+// players, through Session.State.
+func (c *Client) GameState(ctx context.Context, gameID string) (State, error) {
+	var state State
+	err := c.do(ctx, http.MethodGet, "/api/v1/games/"+gameID, "", nil, &state)
+	return state, err
+}
+
+// Join adds a player to a game and starts following it. The session's
+// States channel receives the game state after every change.
+func (c *Client) Join(ctx context.Context, gameID string, opts JoinOptions) (*Session, error) {
+	var resp struct {
+		PlayerID string `json:"playerId"`
+		Token    string `json:"token"`
+		Game     State  `json:"game"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/games/"+gameID+"/players", "", opts, &resp); err != nil {
+		return nil, err
+	}
+	return c.Resume(gameID, resp.PlayerID, resp.Token), nil
+}
+
+// do sends a JSON request and decodes the JSON response into out.
+func (c *Client) do(ctx context.Context, method, path, token string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return readError(resp)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// readError turns an error response into an *APIError.
+func readError(resp *http.Response) error {
+	apiErr := &APIError{Status: resp.StatusCode, Code: "http_error", Message: resp.Status}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		apiErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+
+	var body struct {
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+	if json.Unmarshal(data, &body) == nil && body.Error.Code != "" {
+		apiErr.Code = body.Error.Code
+		apiErr.Message = body.Error.Message
+	} else if text := strings.TrimSpace(string(data)); text != "" {
+		apiErr.Message = text
+	}
+	return apiErr
+}
+
+// wsURL returns the WebSocket address of a path on the server.
+func (c *Client) wsURL(path string) string {
+	base := c.BaseURL
+	switch {
+	case strings.HasPrefix(base, "https://"):
+		base = "wss://" + strings.TrimPrefix(base, "https://")
+	case strings.HasPrefix(base, "http://"):
+		base = "ws://" + strings.TrimPrefix(base, "http://")
+	}
+	return base + path
+}
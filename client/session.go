@@ -0,0 +1,317 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"mismo/game"
+
+	"github.com/gorilla/websocket"
+)
+
+// minBackoff is the first wait before reconnecting.
+const minBackoff = 500 * time.Millisecond
+
+// Session is a player in a game. Actions go through the REST API; state
+// updates arrive over a WebSocket that is re-established whenever it drops,
+// for as long as the session is open and the server still accepts the
+// player.
+type Session struct {
+	GameID   string
+	PlayerID string
+	// Token authenticates the player; keep it to Resume the session later.
+	Token string
+
+	client *Client
+	states chan State
+	errors chan error
+	chats  chan ChatMessage
+
+	mu     sync.Mutex
+	conn   *websocket.Conn
+	closed bool
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// Resume follows a game as a player who has already joined, for example
+// after a bot restarts.
+func (c *Client) Resume(gameID, playerID, token string) *Session {
+	s := &Session{
+		GameID:   gameID,
+		PlayerID: playerID,
+		Token:    token,
+		client:   c,
+		states:   make(chan State, 1),
+		errors:   make(chan error, 8),
+		chats:    make(chan ChatMessage, 16),
+		done:     make(chan struct{}),
+	}
+	s.wg.Add(1)
+	go s.run()
+	return s
+}
+
+// States delivers the game state after every change. Only the latest state
+// is kept for a slow reader; intermediate states are dropped. The channel is
+// closed by Close, or once the server rejects the player for good, for
+// example for an invalid token or a game that no longer exists; the reason
+// is sent on Errors first.
+func (s *Session) States() <-chan State {
+	return s.states
+}
+
+// Errors reports connection failures and errors the server sent over the
+// WebSocket. Errors are dropped when nobody reads them.
+func (s *Session) Errors() <-chan error {
+	return s.errors
+}
+
+// Chats delivers messages from teammates in a team game, including the
+// player's own. Messages are dropped when nobody reads them.
+func (s *Session) Chats() <-chan ChatMessage {
+	return s.chats
+}
+
+// State fetches the game's state as the player sees it.
+func (s *Session) State(ctx context.Context) (State, error) {
+	var state State
+	err := s.client.do(ctx, http.MethodGet, "/api/v1/games/"+s.GameID, s.Token, nil, &state)
+	return state, err
+}
+
+// Submit submits a number for the current round. In games with lock-in it
+// may be changed until Lock is called; otherwise it is final.
+func (s *Session) Submit(ctx context.Context, number game.Number) (State, error) {
+	var state State
+	err := s.client.do(ctx, http.MethodPost, "/api/v1/games/"+s.GameID+"/submissions", s.Token,
+		map[string]game.Number{"number": number}, &state)
+	return state, err
+}
+
+// Lock locks in the submitted number.
+func (s *Session) Lock(ctx context.Context) (State, error) {
+	var state State
+	err := s.client.do(ctx, http.MethodPost, "/api/v1/games/"+s.GameID+"/locks", s.Token, nil, &state)
+	return state, err
+}
+
+// PlayCard plays a card from the player's hand this round. Peeks and swaps
+// need a target player.
+func (s *Session) PlayCard(ctx context.Context, card game.Card, target string) (State, error) {
+	var state State
+	err := s.client.do(ctx, http.MethodPost, "/api/v1/games/"+s.GameID+"/cards", s.Token,
+		map[string]interface{}{"card": card, "target": target}, &state)
+	return state, err
+}
+
+// Wager stakes one of the player's lives on not losing one this round.
+func (s *Session) Wager(ctx context.Context) (State, error) {
+	var state State
+	err := s.client.do(ctx, http.MethodPost, "/api/v1/games/"+s.GameID+"/wagers", s.Token, nil, &state)
+	return state, err
+}
+
+// Chat sends a message to the player's team.
+func (s *Session) Chat(ctx context.Context, text string) error {
+	return s.client.do(ctx, http.MethodPost, "/api/v1/games/"+s.GameID+"/chat", s.Token,
+		map[string]string{"text": text}, nil)
+}
+
+// AssignTeam moves a player to a team before the game starts. Only the host
+// can do this.
+func (s *Session) AssignTeam(ctx context.Context, playerID string, team int) (State, error) {
+	var state State
+	err := s.client.do(ctx, http.MethodPost, "/api/v1/games/"+s.GameID+"/teams", s.Token,
+		map[string]interface{}{"playerId": playerID, "team": team}, &state)
+	return state, err
+}
+
+// BalanceTeams deals the players out evenly to the teams. Only the host can
+// do this.
+func (s *Session) BalanceTeams(ctx context.Context) (State, error) {
+	var state State
+	err := s.client.do(ctx, http.MethodPost, "/api/v1/games/"+s.GameID+"/teams/balance", s.Token, nil, &state)
+	return state, err
+}
+
+// Start starts the game. Only the host can start it.
+func (s *Session) Start(ctx context.Context) (State, error) {
+	return s.round(ctx)
+}
+
+// NextRound moves on from an evaluated round. Only the host can do this.
+func (s *Session) NextRound(ctx context.Context) (State, error) {
+	return s.round(ctx)
+}
+
+func (s *Session) round(ctx context.Context) (State, error) {
+	var state State
+	err := s.client.do(ctx, http.MethodPost, "/api/v1/games/"+s.GameID+"/rounds", s.Token, nil, &state)
+	return state, err
+}
+
+// Close stops following the game and closes the States channel. The player
+// stays in the game.
+func (s *Session) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.done)
+	conn := s.conn
+	s.mu.Unlock()
+
+	var err error
+	if conn != nil {
+		err = conn.Close()
+	}
+	s.wg.Wait()
+	return err
+}
+
+// run keeps a WebSocket open until the session is closed, backing off
+// between failed attempts. It gives up on errors that retrying cannot fix.
+func (s *Session) run() {
+	defer s.wg.Done()
+	defer close(s.states)
+
+	backoff := minBackoff
+	for {
+		connected, err := s.follow()
+		if err != nil {
+			s.report(err)
+			if permanent(err) {
+				return
+			}
+		}
+		if connected {
+			backoff = minBackoff
+		}
+
+		select {
+		case <-s.done:
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > s.client.MaxBackoff {
+			backoff = s.client.MaxBackoff
+		}
+	}
+}
+
+// follow connects, attaches to the player and reads states until the
+// connection fails. It reports whether the player was attached.
+func (s *Session) follow() (bool, error) {
+	conn, resp, err := s.client.Dialer.Dial(s.client.wsURL("/ws/game/"+s.GameID), nil)
+	if err != nil {
+		if resp != nil && resp.StatusCode != http.StatusSwitchingProtocols {
+			return false, readError(resp)
+		}
+		return false, err
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		conn.Close()
+		return false, nil
+	}
+	s.conn = conn
+	s.mu.Unlock()
+	defer conn.Close()
+
+	if err := conn.WriteJSON(map[string]string{"type": "join", "token": s.Token}); err != nil {
+		return false, s.unlessClosed(err)
+	}
+
+	attached := false
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return attached, s.unlessClosed(err)
+		}
+
+		var msg struct {
+			Type  string `json:"type"`
+			Error string `json:"error"`
+		}
+		if err := json.Unmarshal(data, &msg); err != nil {
+			continue
+		}
+		switch {
+		case msg.Error != "" && !attached:
+			// The only message sent before "joined" rejects the token.
+			return false, &APIError{Status: http.StatusUnauthorized, Code: "invalid_token", Message: msg.Error}
+		case msg.Error != "":
+			s.report(&APIError{Code: "ws_error", Message: msg.Error})
+		case msg.Type == "joined":
+			attached = true
+		case msg.Type == "state":
+			var state State
+			if err := json.Unmarshal(data, &state); err == nil {
+				s.publish(state)
+			}
+		case msg.Type == "chat":
+			var chat ChatMessage
+			if err := json.Unmarshal(data, &chat); err == nil {
+				select {
+				case s.chats <- chat:
+				default:
+				}
+			}
+		}
+	}
+}
+
+// permanent reports whether an error means the player can no longer follow
+// the game: the token is rejected or the game is gone.
+func permanent(err error) bool {
+	apiErr, ok := err.(*APIError)
+	if !ok {
+		return false
+	}
+	switch apiErr.Status {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusGone:
+		return true
+	}
+	return false
+}
+
+// publish delivers a state, replacing one the reader has not taken yet.
+func (s *Session) publish(state State) {
+	for {
+		select {
+		case s.states <- state:
+			return
+		default:
+		}
+		select {
+		case <-s.states:
+		default:
+		}
+	}
+}
+
+func (s *Session) report(err error) {
+	select {
+	case s.errors <- err:
+	default:
+	}
+}
+
+// unlessClosed drops errors caused by Close.
+func (s *Session) unlessClosed(err error) error {
+	select {
+	case <-s.done:
+		return nil
+	default:
+		return err
+	}
+}
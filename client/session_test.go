@@ -0,0 +1,176 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+var upgrader = websocket.Upgrader{}
+
+// fakeServer serves /ws/game/{id} with handle, counting connection attempts.
+func fakeServer(t *testing.T, handle func(w http.ResponseWriter, r *http.Request, attempt int32)) (*Client, *int32) {
+	t.Helper()
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handle(w, r, atomic.AddInt32(&attempts, 1))
+	}))
+	t.Cleanup(server.Close)
+	c := New(server.URL)
+	c.MaxBackoff = time.Second
+	return c, &attempts
+}
+
+// accept upgrades the connection and reads the join message.
+func accept(t *testing.T, w http.ResponseWriter, r *http.Request) *websocket.Conn {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		t.Errorf("upgrade: %v", err)
+		return nil
+	}
+	var join map[string]string
+	if err := conn.ReadJSON(&join); err != nil || join["type"] != "join" || join["token"] != "tok" {
+		t.Errorf("join = %v, %v", join, err)
+	}
+	return conn
+}
+
+// waitClosed waits for the States channel to close, draining states.
+func waitClosed(t *testing.T, s *Session) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case _, ok := <-s.States():
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("States was not closed")
+		}
+	}
+}
+
+func TestSessionStopsOnPermanentErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		handle func(t *testing.T, w http.ResponseWriter, r *http.Request)
+		status int
+		code   string
+	}{
+		{
+			name: "unknown game",
+			handle: func(t *testing.T, w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "Game not found.", http.StatusNotFound)
+			},
+			status: http.StatusNotFound,
+			code:   "http_error",
+		},
+		{
+			name: "invalid token",
+			handle: func(t *testing.T, w http.ResponseWriter, r *http.Request) {
+				conn := accept(t, w, r)
+				if conn == nil {
+					return
+				}
+				defer conn.Close()
+				conn.WriteJSON(map[string]string{"error": "Invalid token."})
+				// The client hangs up rather than waiting on a rejected player.
+				conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+				_, _, err := conn.ReadMessage()
+				if ne, ok := err.(net.Error); ok && ne.Timeout() {
+					t.Error("connection was not closed")
+				}
+			},
+			status: http.StatusUnauthorized,
+			code:   "invalid_token",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, attempts := fakeServer(t, func(w http.ResponseWriter, r *http.Request, attempt int32) {
+				tt.handle(t, w, r)
+			})
+			s := c.Resume("ABC123", "p1", "tok")
+			defer s.Close()
+
+			waitClosed(t, s)
+			var apiErr *APIError
+			select {
+			case err := <-s.Errors():
+				if !errors.As(err, &apiErr) || apiErr.Status != tt.status || apiErr.Code != tt.code {
+					t.Fatalf("error = %v, want %d %s", err, tt.status, tt.code)
+				}
+			default:
+				t.Fatal("no error was reported")
+			}
+			time.Sleep(2 * minBackoff)
+			if n := atomic.LoadInt32(attempts); n != 1 {
+				t.Errorf("connected %d times, want 1", n)
+			}
+		})
+	}
+}
+
+func TestSessionRetriesTransientErrors(t *testing.T) {
+	c, attempts := fakeServer(t, func(w http.ResponseWriter, r *http.Request, attempt int32) {
+		if attempt == 1 {
+			http.Error(w, "Busy.", http.StatusServiceUnavailable)
+			return
+		}
+		conn := accept(t, w, r)
+		if conn == nil {
+			return
+		}
+		defer conn.Close()
+		conn.WriteJSON(map[string]string{"type": "joined", "playerId": "p1"})
+		conn.WriteJSON(map[string]interface{}{"type": "state", "id": "ABC123", "round": 3})
+		conn.ReadMessage()
+	})
+	s := c.Resume("ABC123", "p1", "tok")
+	defer s.Close()
+
+	select {
+	case state := <-s.States():
+		if state.ID != "ABC123" || state.Round != 3 {
+			t.Errorf("state = %+v", state)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no state after the server recovered")
+	}
+	if err := <-s.Errors(); !errors.As(err, new(*APIError)) || permanent(err) {
+		t.Errorf("error = %v, want a transient API error", err)
+	}
+	if n := atomic.LoadInt32(attempts); n != 2 {
+		t.Errorf("connected %d times, want 2", n)
+	}
+}
+
+func TestPermanent(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&APIError{Status: http.StatusUnauthorized}, true},
+		{&APIError{Status: http.StatusForbidden}, true},
+		{&APIError{Status: http.StatusNotFound}, true},
+		{&APIError{Status: http.StatusGone}, true},
+		{&APIError{Status: http.StatusTooManyRequests}, false},
+		{&APIError{Status: http.StatusServiceUnavailable}, false},
+		{&APIError{Code: "ws_error"}, false},
+		{errors.New("connection reset"), false},
+		{&json.SyntaxError{}, false},
+	}
+	for _, tt := range tests {
+		if got := permanent(tt.err); got != tt.want {
+			t.Errorf("permanent(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
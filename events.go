@@ -0,0 +1,117 @@
+// events.go
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"mismo/auth"
+)
+
+// Clients that cannot keep a WebSocket open can follow a game as
+// Server-Sent Events instead: a "state" event with the player's view of the
+// game after every change. Event IDs are versions of the game, so a client
+// reconnecting with Last-Event-ID only receives a state if it missed one.
+// EventSource cannot set headers, so besides a bearer token the stream
+// accepts the session cookie set when joining over REST.
+
+// keepAliveInterval is how often an idle event stream gets a comment, so
+// that proxies do not close it.
+const keepAliveInterval = 15 * time.Second
+
+// changed records a change to the game and wakes up its event streams.
+// Callers must hold g.mu.
+func (g *Game) changed() {
+	g.version++
+	for ch := range g.streams {
+		select {
+		case ch <- struct{}{}:
+		default:
+			// A wake-up is already pending; the stream reads the latest state.
+		}
+	}
+}
+
+// subscribe returns a channel that receives a value whenever the game
+// changes.
+func (g *Game) subscribe() chan struct{} {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	ch := make(chan struct{}, 1)
+	g.streams[ch] = true
+	return ch
+}
+
+func (g *Game) unsubscribe(ch chan struct{}) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.streams, ch)
+}
+
+// apiEvents streams the player's view of the game until the client goes
+// away, the player leaves or the game is dropped.
+func apiEvents(w http.ResponseWriter, r *http.Request, g *Game, playerID string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeAPIError(w, http.StatusInternalServerError, "internal", "streaming not supported")
+		return
+	}
+	lastID, _ := strconv.Atoi(r.Header.Get("Last-Event-ID"))
+
+	updates := g.subscribe()
+	defer g.unsubscribe(updates)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	fmt.Fprint(w, "retry: 2000\n\n")
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		if _, ok := findGame(g.ID); !ok || !g.hasPlayer(playerID) {
+			return
+		}
+		g.mu.Lock()
+		version := g.version
+		g.mu.Unlock()
+
+		// A last ID ahead of the game comes from before a restart.
+		if lastID > version {
+			lastID = 0
+		}
+		if version > lastID {
+			writeEvent(w, "state", version, g.state(playerID))
+			lastID = version
+		}
+		flusher.Flush()
+
+		select {
+		case <-updates:
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// writeEvent writes one Server-Sent Event with a JSON payload.
+func writeEvent(w http.ResponseWriter, event string, id int, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "event: %s\nid: %d\ndata: %s\n\n", event, id, data)
+}
+
+// streamToken returns the player token of an event stream request.
+func streamToken(r *http.Request) string {
+	return auth.RequestToken(r)
+}
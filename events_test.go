@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"mismo/auth"
+)
+
+// joinAPI joins a game over REST and returns the response.
+func joinAPI(t *testing.T, server *httptest.Server, gameID string, req joinRequest) joinResponse {
+	t.Helper()
+	body, _ := json.Marshal(req)
+	resp, err := http.Post(server.URL+"/api/v1/games/"+gameID+"/players", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("joining as %s: status %d", req.Name, resp.StatusCode)
+	}
+	var joined joinResponse
+	if err := json.NewDecoder(resp.Body).Decode(&joined); err != nil {
+		t.Fatal(err)
+	}
+	return joined
+}
+
+// sseEvent is one Server-Sent Event.
+type sseEvent struct {
+	name string
+	id   int
+	data string
+}
+
+// openEvents opens the event stream of a game and returns a function
+// reading the next event.
+func openEvents(t *testing.T, server *httptest.Server, gameID string, header http.Header) func() sseEvent {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/api/v1/games/"+gameID+"/events", nil)
+	req.Header = header
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("opening events: status %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+
+	lines := bufio.NewScanner(resp.Body)
+	lines.Buffer(nil, 1<<20)
+	return func() sseEvent {
+		t.Helper()
+		var event sseEvent
+		for lines.Scan() {
+			field, value, _ := strings.Cut(lines.Text(), ": ")
+			switch field {
+			case "event":
+				event.name = value
+			case "id":
+				event.id, _ = strconv.Atoi(value)
+			case "data":
+				event.data = value
+			case "":
+				if event.name != "" {
+					return event
+				}
+			}
+		}
+		t.Fatalf("event stream ended: %v", lines.Err())
+		return event
+	}
+}
+
+func TestEvents(t *testing.T) {
+	server := testServer(t)
+	g := testGame(t, defaultOptions())
+	ana := joinAPI(t, server, g.ID, joinRequest{Name: "ana"})
+
+	next := openEvents(t, server, g.ID, http.Header{"Authorization": {"Bearer " + ana.Token}})
+	first := next()
+	var state stateMessage
+	if err := json.Unmarshal([]byte(first.data), &state); err != nil || first.name != "state" {
+		t.Fatalf("first event = %q %q, want a state", first.name, first.data)
+	}
+	if _, ok := state.Players[ana.PlayerID]; !ok {
+		t.Error("state does not list the player")
+	}
+
+	joinAPI(t, server, g.ID, joinRequest{Name: "bea"})
+	second := next()
+	if second.id <= first.id {
+		t.Errorf("event IDs went from %d to %d", first.id, second.id)
+	}
+
+	// A client that resumes where it left off only gets new states.
+	resumed := openEvents(t, server, g.ID, http.Header{
+		"Authorization": {"Bearer " + ana.Token},
+		"Last-Event-Id": {strconv.Itoa(second.id)},
+	})
+	joinAPI(t, server, g.ID, joinRequest{Name: "cai"})
+	if event := resumed(); event.id != second.id+1 {
+		t.Errorf("resumed stream sent event %d, want %d", event.id, second.id+1)
+	}
+
+	// Browsers authenticate with the session cookie set on joining.
+	viaCookie := openEvents(t, server, g.ID, http.Header{"Cookie": {auth.SessionCookie + "=" + ana.Token}})
+	if event := viaCookie(); event.name != "state" {
+		t.Errorf("cookie stream sent %q, want a state", event.name)
+	}
+}
+
+func TestEventsNeedPlayerToken(t *testing.T) {
+	server := testServer(t)
+	g := testGame(t, defaultOptions())
+	joinAPI(t, server, g.ID, joinRequest{Name: "ana"})
+
+	for name, header := range map[string]http.Header{
+		"no token":      nil,
+		"invalid token": {"Authorization": {"Bearer nope"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, server.URL+"/api/v1/games/"+g.ID+"/events", nil)
+			req.Header = header
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusUnauthorized {
+				t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+			}
+		})
+	}
+}
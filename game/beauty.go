@@ -0,0 +1,147 @@
+package game
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+var ErrInvalidFraction = fmt.Errorf("%w: fraction must be a ratio or decimal of whole numbers", ErrInvalidOptions)
+
+// Fraction is an exact non-negative rational number, encoded in JSON as a
+// string such as "2/3" or "0.5".
+type Fraction struct {
+	r *big.Rat // nil means 0; never modified once set
+}
+
+// DefaultBeautyFactor is the p of a beauty contest unless the options set
+// another.
+var DefaultBeautyFactor = NewFraction(2, 3)
+
+func NewFraction(num, den uint64) Fraction {
+	return Fraction{r: new(big.Rat).SetFrac(new(big.Int).SetUint64(num), new(big.Int).SetUint64(den))}
+}
+
+// ParseFraction parses a fraction written as a whole number, a ratio of
+// whole numbers or a decimal. Signs, exponents, zero denominators and
+// fractions longer than MaxNumberDigits are rejected with
+// ErrInvalidFraction.
+func ParseFraction(s string) (Fraction, error) {
+	if s == "" || len(s) > MaxNumberDigits || strings.Count(s, "/")+strings.Count(s, ".") > 1 {
+		return Fraction{}, ErrInvalidFraction
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && c != '/' && c != '.' {
+			return Fraction{}, ErrInvalidFraction
+		}
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return Fraction{}, ErrInvalidFraction
+	}
+	return Fraction{r: r}, nil
+}
+
+func (f Fraction) rat() *big.Rat {
+	if f.r == nil {
+		return new(big.Rat)
+	}
+	return f.r
+}
+
+func (f Fraction) String() string {
+	return f.rat().RatString()
+}
+
+func (f Fraction) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + f.String() + `"`), nil
+}
+
+func (f *Fraction) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) >= 2 && data[0] == '"' && data[len(data)-1] == '"' {
+		data = data[1 : len(data)-1]
+	}
+	parsed, err := ParseFraction(string(data))
+	if err != nil {
+		return err
+	}
+	*f = parsed
+	return nil
+}
+
+// beautyFactor returns the p of a beauty contest.
+func (o Options) beautyFactor() Fraction {
+	if o.BeautyFactor == nil {
+		return DefaultBeautyFactor
+	}
+	return *o.BeautyFactor
+}
+
+// evaluateBeauty applies ModeBeautyContest to the submitted numbers. Mismos
+// are penalised as in classic rounds, and the distances are compared
+// exactly, so players equally far from the goal always share the outcome.
+func (g *Game) evaluateBeauty(result *RoundResult, players []*Player) {
+	picks := picksOf(players)
+	sum := new(big.Int)
+	for _, pick := range picks {
+		sum.Add(sum, pick.Number.int())
+	}
+	if len(picks) == 0 {
+		return
+	}
+
+	goal := new(big.Rat).SetFrac(sum, big.NewInt(int64(len(picks))))
+	goal.Mul(goal, g.Options.beautyFactor().rat())
+	result.Goal = &Fraction{r: goal}
+
+	penalties := g.Options.Ties.Apply(picks)
+	inMismo := make(map[string]bool)
+	for _, id := range penalties.Mismo {
+		inMismo[id] = true
+		if g.Options.Ties.Penalty == TieEliminate {
+			g.Players[id].Lives = 0
+		} else {
+			g.Players[id].Lives--
+		}
+	}
+	result.Mismo = append(result.Mismo, penalties.Mismo...)
+	result.Min, result.Max = penalties.Min, penalties.Max
+
+	var contenders []Pick
+	for _, pick := range picks {
+		if !(g.Options.Ties.MismoOverridesExtreme && inMismo[pick.PlayerID]) {
+			contenders = append(contenders, pick)
+		}
+	}
+	for _, id := range furthestFromGoal(contenders, goal) {
+		g.Players[id].Lives--
+		result.LostLife = append(result.LostLife, id)
+	}
+}
+
+// furthestFromGoal returns the players whose numbers are furthest from the
+// goal, or none when every player is equally far.
+func furthestFromGoal(picks []Pick, goal *big.Rat) []string {
+	distances := make([]*big.Rat, len(picks))
+	var furthest *big.Rat
+	for i, pick := range picks {
+		d := new(big.Rat).SetInt(pick.Number.int())
+		distances[i] = d.Abs(d.Sub(d, goal))
+		if furthest == nil || distances[i].Cmp(furthest) > 0 {
+			furthest = distances[i]
+		}
+	}
+
+	var losers []string
+	for i, pick := range picks {
+		if distances[i].Cmp(furthest) == 0 {
+			losers = append(losers, pick.PlayerID)
+		}
+	}
+	if len(losers) == len(picks) {
+		return nil
+	}
+	return losers
+}
@@ -0,0 +1,248 @@
+package game
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"sort"
+)
+
+var (
+	ErrNoCards       = errors.New("game has no cards")
+	ErrCardNotHeld   = errors.New("player does not hold that card")
+	ErrCardPlayed    = errors.New("a card was already played this round")
+	ErrInvalidTarget = errors.New("card needs another living player as target")
+	ErrNothingToPeek = errors.New("target has no number from the last round")
+)
+
+// Card is a power-up a player may play once, alongside their number.
+//
+// Cards take effect in a fixed order when the round is evaluated: swaps
+// first, in the seat order of the players who played them, then the
+// round's rules, then shields, then double-downs, before any wagers are
+// settled. A peek takes effect as soon as it is played.
+type Card string
+
+const (
+	// CardShield cancels the lives the player loses to the round's rule,
+	// such as being the minimum or maximum, but not to a mismo.
+	CardShield Card = "shield"
+	// CardDoubleDown doubles the lives the player loses this round, or
+	// gives them a life back, up to the starting lives, if they lose none.
+	// It cannot take more lives than the player has left, so it makes no
+	// difference to a player already knocked out this round.
+	CardDoubleDown Card = "doubleDown"
+	// CardPeek shows the player the target's number from the last round.
+	CardPeek Card = "peek"
+	// CardSwap exchanges the player's number with the target's before the
+	// round is judged. It does nothing if either has not submitted.
+	CardSwap Card = "swap"
+)
+
+// Cards lists every kind of card; a deck holds cardCopies of each.
+var Cards = []Card{CardShield, CardDoubleDown, CardPeek, CardSwap}
+
+const cardCopies = 4
+
+// MaxHand is the most cards a player can hold; cards dealt beyond it are
+// lost.
+const MaxHand = 3
+
+func (c Card) needsTarget() bool {
+	return c == CardPeek || c == CardSwap
+}
+
+// CardPlay is a card played in a round. Peeked is the target's number from
+// the last round for a peek.
+type CardPlay struct {
+	PlayerID string  `json:"playerId"`
+	Card     Card    `json:"card"`
+	Target   string  `json:"target,omitempty"`
+	Peeked   *Number `json:"peeked,omitempty"`
+}
+
+// Deck is a shuffled pile of cards. A fresh deck is shuffled in when it
+// runs out.
+type Deck struct {
+	cards []Card
+}
+
+// Draw takes the top card.
+func (d *Deck) Draw() (Card, error) {
+	if len(d.cards) == 0 {
+		if err := d.shuffle(); err != nil {
+			return "", err
+		}
+	}
+	card := d.cards[len(d.cards)-1]
+	d.cards = d.cards[:len(d.cards)-1]
+	return card, nil
+}
+
+func (d *Deck) shuffle() error {
+	d.cards = d.cards[:0]
+	for _, card := range Cards {
+		for i := 0; i < cardCopies; i++ {
+			d.cards = append(d.cards, card)
+		}
+	}
+	for i := len(d.cards) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return err
+		}
+		d.cards[i], d.cards[j.Int64()] = d.cards[j.Int64()], d.cards[i]
+	}
+	return nil
+}
+
+// dealCards gives every living player a card on the rounds the options
+// deal them.
+func (g *Game) dealCards() error {
+	if g.Options.CardEvery == 0 || g.Round%g.Options.CardEvery != 0 {
+		return nil
+	}
+	for _, p := range g.living() {
+		card, err := g.deck.Draw()
+		if err != nil {
+			return err
+		}
+		if len(p.Cards) < MaxHand {
+			p.Cards = append(p.Cards, card)
+		}
+	}
+	return nil
+}
+
+// PlayCard plays a card from a player's hand in the current round, before
+// their number is locked. Peeks and swaps need another living player as
+// target. One card can be played per round.
+func (g *Game) PlayCard(playerID string, card Card, target string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	player, exists := g.Players[playerID]
+	if !exists {
+		return ErrPlayerNotFound
+	}
+	if g.Options.CardEvery == 0 {
+		return ErrNoCards
+	}
+	if player.Lives <= 0 {
+		return ErrEliminated
+	}
+	if g.State != Playing {
+		return ErrNotPlaying
+	}
+	if player.Locked {
+		return ErrNumberLocked
+	}
+	if player.Play != nil {
+		return ErrCardPlayed
+	}
+	held := -1
+	for i, c := range player.Cards {
+		if c == card {
+			held = i
+			break
+		}
+	}
+	if held < 0 {
+		return ErrCardNotHeld
+	}
+
+	play := &CardPlay{PlayerID: playerID, Card: card}
+	if card.needsTarget() {
+		other, exists := g.Players[target]
+		if !exists || other == player || other.Lives <= 0 {
+			return ErrInvalidTarget
+		}
+		play.Target = target
+	}
+	if card == CardPeek {
+		n, ok := Number{}, false
+		if g.LastRound != nil {
+			n, ok = g.LastRound.Numbers[target]
+		}
+		if !ok {
+			return ErrNothingToPeek
+		}
+		play.Peeked = &n
+	}
+
+	player.Cards = append(player.Cards[:held:held], player.Cards[held+1:]...)
+	player.Play = play
+	return nil
+}
+
+// plays returns the cards played this round, in seat order.
+func (g *Game) plays() []*CardPlay {
+	var plays []*CardPlay
+	for _, p := range g.seated() {
+		if p.Play != nil {
+			plays = append(plays, p.Play)
+		}
+	}
+	return plays
+}
+
+// applySwaps exchanges numbers for the swaps played this round.
+func (g *Game) applySwaps() {
+	for _, play := range g.plays() {
+		if play.Card != CardSwap {
+			continue
+		}
+		p, target := g.Players[play.PlayerID], g.Players[play.Target]
+		if target == nil || target.Lives <= 0 || p.Number == nil || target.Number == nil {
+			continue
+		}
+		p.Number, target.Number = target.Number, p.Number
+	}
+}
+
+// applyCardEffects applies shields and then double-downs, given the lives
+// players had before the round.
+func (g *Game) applyCardEffects(result *RoundResult, before map[string]int) {
+	plays := g.plays()
+	for _, play := range plays {
+		if play.Card != CardShield {
+			continue
+		}
+		var kept []string
+		for _, id := range result.LostLife {
+			if id == play.PlayerID {
+				g.Players[id].Lives++
+				continue
+			}
+			kept = append(kept, id)
+		}
+		if len(kept) < len(result.LostLife) {
+			result.Shielded = append(result.Shielded, play.PlayerID)
+		}
+		result.LostLife = append([]string{}, kept...)
+	}
+	for _, play := range plays {
+		if play.Card != CardDoubleDown {
+			continue
+		}
+		p := g.Players[play.PlayerID]
+		left := max(p.Lives, 0)
+		lost := before[p.ID] - left
+		switch {
+		case lost > 0:
+			extra := min(lost, left)
+			p.Lives -= extra
+			for i := 0; i < extra; i++ {
+				result.LostLife = append(result.LostLife, p.ID)
+			}
+		case lost == 0 && p.Lives < g.Options.Lives:
+			p.Lives++
+			result.GainedLife = append(result.GainedLife, p.ID)
+		}
+	}
+
+	for _, play := range plays {
+		result.Cards = append(result.Cards, *play)
+	}
+	sort.Strings(result.Shielded)
+}
@@ -0,0 +1,85 @@
+package game
+
+import "testing"
+
+func TestDoubleDownStacksDownToZero(t *testing.T) {
+	// Without MismoOverridesExtreme a mismo on the minimum costs two lives.
+	stacking := TieRules{MismoMin: 2, Penalty: TieLoseLife, SharedExtremes: true}
+	tests := []struct {
+		name    string
+		ties    TieRules
+		lives   int
+		numbers map[string]uint64
+		want    int // lives "a" is left with
+	}{
+		{
+			name:    "mismo on the minimum with lives to spare",
+			ties:    stacking,
+			lives:   5,
+			numbers: map[string]uint64{"a": 1, "b": 1, "c": 5, "d": 9},
+			want:    1,
+		},
+		{
+			name:    "mismo on the minimum doubled past zero",
+			ties:    stacking,
+			lives:   3,
+			numbers: map[string]uint64{"a": 1, "b": 1, "c": 5, "d": 9},
+			want:    0,
+		},
+		{
+			name:    "mismo on the minimum already costs every life",
+			ties:    stacking,
+			lives:   2,
+			numbers: map[string]uint64{"a": 1, "b": 1, "c": 5, "d": 9},
+			want:    0,
+		},
+		{
+			name:    "extreme alone doubled",
+			ties:    stacking,
+			lives:   3,
+			numbers: map[string]uint64{"a": 9, "b": 1, "c": 5, "d": 6},
+			want:    1,
+		},
+		{
+			name:    "extreme alone on the last life",
+			ties:    stacking,
+			lives:   1,
+			numbers: map[string]uint64{"a": 9, "b": 1, "c": 5, "d": 6},
+			want:    0,
+		},
+		{
+			name:    "classic mismo eliminates, doubled",
+			ties:    ClassicTieRules,
+			lives:   4,
+			numbers: map[string]uint64{"a": 5, "b": 5, "c": 1, "d": 9},
+			want:    0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.Lives = tt.lives
+			opts.Ties = tt.ties
+			opts.CardEvery = 1
+			g := startGame(t, opts, "a", "b", "c", "d")
+			g.Players["a"].Cards = []Card{CardDoubleDown}
+			if err := g.PlayCard("a", CardDoubleDown, ""); err != nil {
+				t.Fatalf("PlayCard: %v", err)
+			}
+			submitAll(t, g, tt.numbers)
+
+			a := g.Players["a"]
+			if a.Lives != tt.want {
+				t.Errorf("lives = %d, want %d", a.Lives, tt.want)
+			}
+			for id, n := range lives(g) {
+				if n < 0 {
+					t.Errorf("%s has %d lives", id, n)
+				}
+			}
+			if tt.want == 0 && a.EliminatedRound != 1 {
+				t.Errorf("eliminated in round %d, want 1", a.EliminatedRound)
+			}
+		})
+	}
+}
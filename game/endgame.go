@@ -0,0 +1,139 @@
+package game
+
+import (
+	"crypto/rand"
+	"math/big"
+	"time"
+)
+
+// Phase tells which rules the current round is played under.
+type Phase string
+
+const (
+	PhaseNormal  Phase = "normal"
+	PhaseEndgame Phase = "endgame"
+)
+
+// Endgame is a rule that replaces the normal one when few players are
+// left. With two players the normal rule makes one the minimum and the
+// other the maximum every round, so both always lose a life.
+type Endgame string
+
+const (
+	// EndgameNone keeps the normal rules until the end.
+	EndgameNone Endgame = ""
+	// EndgameTarget draws a hidden target each round; the players furthest
+	// from it lose a life. Nobody loses when all are equally far.
+	EndgameTarget Endgame = "target"
+	// EndgameParity counts out a loser: the sum of the numbers, modulo the
+	// number of players, picks the player in seat order who loses a life.
+	EndgameParity Endgame = "parity"
+	// EndgameSuddenDeath drops every player to their last life; the players
+	// furthest from a hidden target are eliminated, and the round is
+	// replayed when all are equally far, as after a mismo.
+	EndgameSuddenDeath Endgame = "suddenDeath"
+)
+
+func (e Endgame) valid() bool {
+	switch e {
+	case EndgameNone, EndgameTarget, EndgameParity, EndgameSuddenDeath:
+		return true
+	}
+	return false
+}
+
+// startRound opens a round, entering the endgame once few enough players
+// are left.
+func (g *Game) startRound() error {
+	living := g.living()
+	if g.Phase != PhaseEndgame && g.Options.Endgame != EndgameNone && len(living) <= g.Options.EndgamePlayers {
+		g.Phase = PhaseEndgame
+		if g.Options.Endgame == EndgameSuddenDeath {
+			for _, p := range living {
+				p.Lives = 1
+			}
+		}
+	}
+
+	if err := g.dealCards(); err != nil {
+		return err
+	}
+	g.Range = g.roundRange()
+	if g.Phase == PhaseEndgame && g.Options.Endgame != EndgameParity {
+		target, err := g.Range.random()
+		if err != nil {
+			return err
+		}
+		g.target = target
+	}
+	g.roundStarted = time.Now()
+	g.State = Playing
+	return nil
+}
+
+// random draws a number uniformly from the range.
+func (r Range) random() (Number, error) {
+	span := new(big.Int).Sub(r.Max.int(), r.Min.int())
+	n, err := rand.Int(rand.Reader, span.Add(span, big.NewInt(1)))
+	if err != nil {
+		return Number{}, err
+	}
+	return Number{v: n.Add(n, r.Min.int())}, nil
+}
+
+// evaluateEndgame applies the endgame rule to the submitted numbers.
+func (g *Game) evaluateEndgame(result *RoundResult, players []*Player) {
+	if len(players) == 0 {
+		return
+	}
+	result.Min, result.Max = extremes(players)
+
+	var losers []*Player
+	switch g.Options.Endgame {
+	case EndgameParity:
+		sum := new(big.Int)
+		for _, p := range players {
+			sum.Add(sum, p.Number.int())
+		}
+		i := sum.Mod(sum, big.NewInt(int64(len(players)))).Int64()
+		losers = []*Player{players[i]}
+	default:
+		target := g.target
+		result.Target = &target
+		losers = furthestFrom(players, target)
+	}
+
+	for _, p := range losers {
+		if g.Options.Endgame == EndgameSuddenDeath {
+			p.Lives = 0
+		} else {
+			p.Lives--
+		}
+		result.LostLife = append(result.LostLife, p.ID)
+	}
+}
+
+// furthestFrom returns the players whose numbers are furthest from the
+// target, or none when every player is equally far.
+func furthestFrom(players []*Player, target Number) []*Player {
+	distances := make([]*big.Int, len(players))
+	var furthest *big.Int
+	for i, p := range players {
+		d := new(big.Int).Sub(p.Number.int(), target.int())
+		distances[i] = d.Abs(d)
+		if furthest == nil || distances[i].Cmp(furthest) > 0 {
+			furthest = distances[i]
+		}
+	}
+
+	var losers []*Player
+	for i, p := range players {
+		if distances[i].Cmp(furthest) == 0 {
+			losers = append(losers, p)
+		}
+	}
+	if len(losers) == len(players) {
+		return nil
+	}
+	return losers
+}
@@ -2,7 +2,11 @@ package game
 
 import (
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 )
 
 type GameState string
@@ -10,129 +14,633 @@ type GameState string
 const (
 	Waiting  GameState = "waiting"
 	Playing  GameState = "playing"
+	RoundEnd GameState = "roundEnd"
 	Finished GameState = "finished"
 )
 
+var (
+	ErrGameStarted      = errors.New("game has already started")
+	ErrGameFull         = errors.New("game is full")
+	ErrPlayerNotFound   = errors.New("player not found")
+	ErrEliminated       = errors.New("player is eliminated")
+	ErrNotHost          = errors.New("only the host can do this")
+	ErrNotEnoughPlayers = errors.New("not enough players to start")
+	ErrNotPlaying       = errors.New("no round is in progress")
+	ErrRoundInProgress  = errors.New("round is still in progress")
+	ErrGameOver         = errors.New("game is over")
+	ErrNumberLocked     = errors.New("number is already locked")
+	ErrNoNumber         = errors.New("no number to lock")
+)
+
+var ErrInvalidOptions = errors.New("invalid game options")
+
+// MaxRoundSeconds caps Options.RoundSeconds at an hour.
+const MaxRoundSeconds = 60 * 60
+
+// Options are the rules a game is created with.
+type Options struct {
+	Lives      int `json:"lives"`
+	MinPlayers int `json:"minPlayers"`
+	MaxPlayers int `json:"maxPlayers"`
+	// Mode decides normal rounds; see Mode.
+	Mode Mode `json:"mode,omitempty"`
+	// WinnerGainsLife gives the winner of a round in a mode that has one a
+	// life instead of taking one from everyone else. The first player to
+	// double their starting lives wins the game.
+	WinnerGainsLife bool `json:"winnerGainsLife,omitempty"`
+	// Teams splits the players into this many teams sharing their lives;
+	// 0 means every player for themselves.
+	Teams int `json:"teams,omitempty"`
+	// CardEvery deals every living player a card at the start of every
+	// CardEvery-th round; 0 plays without cards.
+	CardEvery int `json:"cardEvery,omitempty"`
+	// Reveal is how much of each round's numbers players see.
+	Reveal Reveal `json:"reveal,omitempty"`
+	// Wagers let players stake a life on a round; see Wager. MedianBonus
+	// gives a life back to the player who picks the exact median. Neither
+	// takes a player above the starting lives.
+	Wagers      bool `json:"wagers,omitempty"`
+	MedianBonus bool `json:"medianBonus,omitempty"`
+	// BeautyFactor is the p of ModeBeautyContest, DefaultBeautyFactor when
+	// unset.
+	BeautyFactor *Fraction `json:"beautyFactor,omitempty"`
+	// MinNumber and MaxNumber bound the numbers players may submit.
+	MinNumber Number `json:"minNumber"`
+	MaxNumber Number `json:"maxNumber"`
+	// Endgame replaces the normal rules once EndgamePlayers or fewer
+	// players are left; see Endgame.
+	Endgame        Endgame  `json:"endgame,omitempty"`
+	EndgamePlayers int      `json:"endgamePlayers,omitempty"`
+	Ties           TieRules `json:"ties"`
+	// LockIn lets players change their number until they lock it. Without
+	// it a submitted number is locked at once.
+	LockIn bool `json:"lockIn,omitempty"`
+	// RoundSeconds, when set, is how long players have to lock a number;
+	// see Game.EvaluateRound. A living player who has not submitted a
+	// number when time runs out loses a life.
+	RoundSeconds int `json:"roundSeconds,omitempty"`
+	// ShrinkWidths narrow the range round by round: round n only accepts
+	// numbers within a span of ShrinkWidths[n-1], the last width holding
+	// for later rounds. Widths are positive and never grow. The span is centred on the middle of the bounds or,
+	// with ShrinkToMedian, on the previous round's median.
+	ShrinkWidths   []Number `json:"shrinkWidths,omitempty"`
+	ShrinkToMedian bool     `json:"shrinkToMedian,omitempty"`
+}
+
+func DefaultOptions() Options {
+	return Options{Lives: 7, MinPlayers: 3, MaxPlayers: 12, MaxNumber: MaxUint64, Ties: DefaultTieRules}
+}
+
+// Validate checks that the options describe a playable game. Errors match
+// ErrInvalidOptions.
+func (o Options) Validate() error {
+	switch {
+	case o.Lives < 1:
+		return fmt.Errorf("%w: lives must be at least 1", ErrInvalidOptions)
+	case o.MinPlayers < 2:
+		return fmt.Errorf("%w: at least 2 players are needed", ErrInvalidOptions)
+	case o.MaxPlayers > 0 && o.MaxPlayers < o.MinPlayers:
+		return fmt.Errorf("%w: minimum players exceeds the maximum", ErrInvalidOptions)
+	case o.MinNumber.Cmp(o.MaxNumber) > 0:
+		return fmt.Errorf("%w: minimum number exceeds the maximum", ErrInvalidOptions)
+	case !o.Mode.valid():
+		return fmt.Errorf("%w: unknown mode %q", ErrInvalidOptions, o.Mode)
+	case o.BeautyFactor != nil && o.BeautyFactor.rat().Sign() <= 0:
+		return fmt.Errorf("%w: the beauty contest factor must be positive", ErrInvalidOptions)
+	case o.Teams < 0 || o.Teams == 1:
+		return fmt.Errorf("%w: a team game needs at least 2 teams", ErrInvalidOptions)
+	case o.MaxPlayers > 0 && o.Teams > o.MaxPlayers:
+		return fmt.Errorf("%w: more teams than players", ErrInvalidOptions)
+	case o.WinnerGainsLife && o.Mode != ModeLowestUnique:
+		return fmt.Errorf("%w: only the lowest unique number mode has a round winner", ErrInvalidOptions)
+	case o.Teams > 0 && o.Mode == ModeLowestUnique:
+		return fmt.Errorf("%w: the lowest unique number mode has no teams", ErrInvalidOptions)
+	case o.CardEvery < 0:
+		return fmt.Errorf("%w: cards cannot be dealt every %d rounds", ErrInvalidOptions, o.CardEvery)
+	case !o.Reveal.valid():
+		return fmt.Errorf("%w: unknown reveal policy %q", ErrInvalidOptions, o.Reveal)
+	case !o.Endgame.valid():
+		return fmt.Errorf("%w: unknown endgame %q", ErrInvalidOptions, o.Endgame)
+	case o.Endgame != EndgameNone && o.EndgamePlayers < 2:
+		return fmt.Errorf("%w: an endgame needs at least 2 players", ErrInvalidOptions)
+	case o.RoundSeconds < 0 || o.RoundSeconds > MaxRoundSeconds:
+		return fmt.Errorf("%w: rounds cannot last %d seconds", ErrInvalidOptions, o.RoundSeconds)
+	case o.ShrinkToMedian && len(o.ShrinkWidths) == 0:
+		return fmt.Errorf("%w: following the median needs shrink widths", ErrInvalidOptions)
+	}
+	if err := o.validateShrinkWidths(); err != nil {
+		return err
+	}
+	return o.Ties.validate()
+}
+
 type Game struct {
-	ID      string             `json:"id"`
-	Players map[string]*Player `json:"players"`
-	State   GameState          `json:"state"`
-	mu      sync.Mutex
+	ID        string             `json:"id"`
+	Players   map[string]*Player `json:"players"`
+	State     GameState          `json:"state"`
+	Phase     Phase              `json:"phase"`
+	Round     int                `json:"round"`
+	Options   Options            `json:"options"`
+	LastRound *RoundResult       `json:"lastRound,omitempty"`
+	// Range is the numbers accepted in the current round.
+	Range Range `json:"range"`
+	// NameRules validate the names of joining players.
+	NameRules NameRules `json:"-"`
+	// target is the hidden number of an endgame round.
+	target Number
+	// roundStarted is when the current round opened.
+	roundStarted time.Time
+	deck         Deck
+	seats        int
+	mu           sync.Mutex
+}
+
+// RoundResult records what happened in an evaluated round. Players are
+// referred to by ID.
+type RoundResult struct {
+	Round   int               `json:"round"`
+	Numbers map[string]Number `json:"numbers"`
+	// Min and Max are the extreme numbers of the round, among those the
+	// round's rule considered.
+	Min        *Number  `json:"min,omitempty"`
+	Max        *Number  `json:"max,omitempty"`
+	LostLife   []string `json:"lostLife"`
+	Mismo      []string `json:"mismo"`
+	Eliminated []string `json:"eliminated"`
+	// TeamMismo lists the teams whose members collided on a number, once
+	// per number.
+	TeamMismo []int `json:"teamMismo,omitempty"`
+	// Cards lists the cards played, and Shielded the players a shield
+	// saved from losing a life.
+	Cards    []CardPlay `json:"cards,omitempty"`
+	Shielded []string   `json:"shielded,omitempty"`
+	// Wagers are the settled wagers, and Median the exact median of the
+	// numbers when the median bonus is played and there is one.
+	Wagers []Wager `json:"wagers,omitempty"`
+	Median *Number `json:"median,omitempty"`
+	// Revealed lists the numbers in order, without who picked them, under
+	// RevealAnonymous; see Project.
+	Revealed []Number `json:"revealed,omitempty"`
+	// Phase is the phase the round was played in, and Target the hidden
+	// number of an endgame that uses one.
+	Phase  Phase   `json:"phase"`
+	Target *Number `json:"target,omitempty"`
+	// Goal is p times the mean of the numbers in a beauty contest.
+	Goal *Fraction `json:"goal,omitempty"`
+	// SubmittedAfter is how long after the round opened each number was
+	// received, in nanoseconds, and LostOnTime the players who lost a
+	// shared minimum or maximum by the speed tie-break.
+	SubmittedAfter map[string]time.Duration `json:"submittedAfter"`
+	LostOnTime     []string                 `json:"lostOnTime,omitempty"`
+	// Missed lists the players who had not submitted a number when the
+	// round timed out; each of them lost a life.
+	Missed []string `json:"missed,omitempty"`
+	// Winner is the player who won the round in a mode that has one, and
+	// GainedLife the players who got a life back.
+	Winner     string   `json:"winner,omitempty"`
+	GainedLife []string `json:"gainedLife,omitempty"`
 }
 
-func NewGame(id string) *Game {
+// Snapshot is a copy of a game's state that is safe to read without locking.
+type Snapshot struct {
+	ID        string       `json:"id"`
+	State     GameState    `json:"state"`
+	Phase     Phase        `json:"phase"`
+	Round     int          `json:"round"`
+	Options   Options      `json:"options"`
+	Range     Range        `json:"range"`
+	Players   []Player     `json:"players"`
+	LastRound *RoundResult `json:"lastRound,omitempty"`
+	// Deadline is when the current round times out, if it does.
+	Deadline *time.Time `json:"deadline,omitempty"`
+	// Standings are set once the game is finished.
+	Standings *Standings `json:"standings,omitempty"`
+}
+
+func NewGame(id string, opts Options) *Game {
 	return &Game{
-		ID:      id,
-		Players: make(map[string]*Player),
-		State:   Waiting,
+		ID:        id,
+		Players:   make(map[string]*Player),
+		State:     Waiting,
+		Phase:     PhaseNormal,
+		Round:     1,
+		Options:   opts,
+		Range:     opts.bounds(),
+		NameRules: DefaultNameRules,
 	}
 }
 
+// AddPlayer adds a player to a game that has not started. The first player
+// to join becomes the host, and every player starts with the lives from the
+// game options.
 func (g *Game) AddPlayer(player *Player) error {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
 	if g.State != Waiting {
-		return errors.New("game has already started")
+		return ErrGameStarted
+	}
+	if g.Options.MaxPlayers > 0 && len(g.Players) >= g.Options.MaxPlayers {
+		return ErrGameFull
+	}
+
+	name, err := g.NameRules.Validate(player.Name)
+	if err != nil {
+		return err
 	}
+	player.Name = g.NameRules.UniqueName(name, func(name string) bool {
+		for _, p := range g.Players {
+			if strings.EqualFold(p.Name, name) {
+				return true
+			}
+		}
+		return false
+	})
 
+	player.IsHost = len(g.Players) == 0
+	player.Lives = g.Options.Lives
+	if g.Options.Teams > 0 {
+		player.Team = g.smallestTeam()
+	}
+	player.Seat = g.seats
+	g.seats++
 	g.Players[player.ID] = player
 	return nil
 }
 
-func (g *Game) SubmitNumber(playerID string, number uint64) error {
+// RemovePlayer removes a player and returns how many remain. When the host
+// leaves, the next player in seat order becomes host. If everyone left in
+// the round has already locked a number, the round is evaluated.
+func (g *Game) RemovePlayer(playerID string) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	player, exists := g.Players[playerID]
+	if !exists {
+		return len(g.Players)
+	}
+	delete(g.Players, playerID)
+	if player.IsHost {
+		g.passHost(player.Seat)
+	}
+	if g.State == Playing && len(g.Players) > 0 && g.allLocked() {
+		g.evaluateRound()
+	}
+	return len(g.Players)
+}
+
+// Start begins the first round. Only the host can start the game.
+func (g *Game) Start(playerID string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err := g.checkHost(playerID); err != nil {
+		return err
+	}
+	if g.State != Waiting {
+		return ErrGameStarted
+	}
+	if len(g.Players) < g.Options.MinPlayers {
+		return ErrNotEnoughPlayers
+	}
+	if g.Options.Teams > 0 {
+		if err := g.checkTeamSizes(); err != nil {
+			return err
+		}
+	}
+	return g.startRound()
+}
+
+// SubmitNumber records a player's number for the current round, replacing
+// the previous one until it is locked. Once every living player has locked
+// a number, the round is evaluated.
+func (g *Game) SubmitNumber(playerID string, number Number) error {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
 	player, exists := g.Players[playerID]
 	if !exists {
-		return errors.New("player not found")
+		return ErrPlayerNotFound
 	}
 
 	if player.Lives <= 0 {
-		return errors.New("player is eliminated")
+		return ErrEliminated
+	}
+
+	if g.State != Playing {
+		return ErrNotPlaying
+	}
+	if player.Locked {
+		return ErrNumberLocked
+	}
+	if err := g.checkNumber(number); err != nil {
+		return err
 	}
 
-	num := number
-	player.Number = &num
+	player.Number = &number
 	player.HasSubmitted = true
+	player.SubmittedAt = time.Now()
+	if !g.Options.LockIn {
+		player.Locked = true
+	}
+
+	if g.allLocked() {
+		g.evaluateRound()
+	}
+	return nil
+}
+
+// LockNumber makes a player's submitted number final. Locking an already
+// locked number does nothing.
+func (g *Game) LockNumber(playerID string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	player, exists := g.Players[playerID]
+	if !exists {
+		return ErrPlayerNotFound
+	}
+	if player.Lives <= 0 {
+		return ErrEliminated
+	}
+	if g.State != Playing {
+		return ErrNotPlaying
+	}
+	if !player.HasSubmitted {
+		return ErrNoNumber
+	}
+	if player.Locked {
+		return nil
+	}
+
+	player.Locked = true
+	if g.allLocked() {
+		g.evaluateRound()
+	}
+	return nil
+}
+
+// NextRound clears the evaluated round and starts the next one. Only the
+// host can move the game on.
+func (g *Game) NextRound(playerID string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err := g.checkHost(playerID); err != nil {
+		return err
+	}
+	switch g.State {
+	case Finished:
+		return ErrGameOver
+	case RoundEnd:
+	default:
+		return ErrRoundInProgress
+	}
+
+	for _, p := range g.Players {
+		p.Number = nil
+		p.HasSubmitted = false
+		p.Locked = false
+		p.Play = nil
+		p.Wagered = false
+	}
+	g.Round++
+	return g.startRound()
+}
+
+// passHost makes the first player seated after seat the host, going back
+// to the first seat if nobody is.
+func (g *Game) passHost(seat int) {
+	players := g.seated()
+	if len(players) == 0 {
+		return
+	}
+	next := players[0]
+	for _, p := range players {
+		if p.Seat > seat {
+			next = p
+			break
+		}
+	}
+	next.IsHost = true
+}
+
+func (g *Game) checkHost(playerID string) error {
+	player, exists := g.Players[playerID]
+	if !exists {
+		return ErrPlayerNotFound
+	}
+	if !player.IsHost {
+		return ErrNotHost
+	}
 	return nil
 }
 
+// AllPlayersSubmitted reports whether every living player has locked a
+// number.
 func (g *Game) AllPlayersSubmitted() bool {
 	g.mu.Lock()
 	defer g.mu.Unlock()
+	return g.allLocked()
+}
 
+func (g *Game) allLocked() bool {
 	for _, p := range g.Players {
-		if p.Lives > 0 && !p.HasSubmitted {
+		if p.Lives > 0 && !p.Locked {
 			return false
 		}
 	}
 	return true
 }
 
-func (g *Game) EvaluateRound() {
+// EvaluateRound ends the given round with the numbers submitted so far,
+// locked or not, for example when a round timer runs out. It reports
+// whether it did: a round that is no longer being played is left alone, so
+// a late timer cannot end the next one.
+func (g *Game) EvaluateRound(round int) bool {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
-	var min, max uint64
-	var minPlayer, maxPlayer *Player
-	numberCount := make(map[uint64][]string)
+	if g.State != Playing || g.Round != round {
+		return false
+	}
+	g.evaluateRound()
+	return true
+}
 
-	// First pass: find duplicates and collect numbers
-	for _, p := range g.Players {
-		if p.Lives <= 0 || p.Number == nil {
-			continue
-		}
-		numberCount[*p.Number] = append(numberCount[*p.Number], p.ID)
+// deadline returns when the current round times out, or nil without a
+// round timer or outside a round.
+func (g *Game) deadline() *time.Time {
+	if g.State != Playing || g.Options.RoundSeconds == 0 {
+		return nil
 	}
+	deadline := g.roundStarted.Add(time.Duration(g.Options.RoundSeconds) * time.Second)
+	return &deadline
+}
 
-	// Handle duplicates (mismo)
-	for num, players := range numberCount {
-		if len(players) > 1 {
-			for _, playerID := range players {
-				g.Players[playerID].Lives--
-			}
-			continue
-		}
-		println(num)
-
-		// Track min and max for single numbers
-		if len(players) == 1 {
-			player := g.Players[players[0]]
-			if minPlayer == nil || *player.Number < min {
-				min = *player.Number
-				minPlayer = player
-			}
-			if maxPlayer == nil || *player.Number > max {
-				max = *player.Number
-				maxPlayer = player
-			}
-		}
+func (g *Game) evaluateRound() {
+	result := &RoundResult{
+		Round:          g.Round,
+		Phase:          g.Phase,
+		Numbers:        make(map[string]Number),
+		SubmittedAfter: make(map[string]time.Duration),
+		LostLife:       []string{},
+		Mismo:          []string{},
+		Eliminated:     []string{},
+	}
+	g.applySwaps()
+	players := g.submitted(result)
+	lives := make(map[string]int)
+	for _, p := range g.living() {
+		lives[p.ID] = p.Lives
+	}
+	if g.Options.Teams > 0 {
+		players = g.teamMismos(result, players)
 	}
 
-	// Reduce lives for min and max players
-	if minPlayer != nil {
-		minPlayer.Lives--
+	switch {
+	case g.Phase == PhaseEndgame:
+		g.evaluateEndgame(result, players)
+	case g.Options.Mode == ModeLowestUnique:
+		g.evaluateLowestUnique(result, players)
+	case g.Options.Mode == ModeBeautyContest:
+		g.evaluateBeauty(result, players)
+	default:
+		g.evaluateNormal(result, players)
+	}
+	g.applyCardEffects(result, lives)
+	g.chargeMissed(result, lives)
+	if g.Options.Wagers {
+		g.settleWagers(result)
+	}
+	if g.Options.MedianBonus {
+		g.awardMedianBonus(result)
 	}
-	if maxPlayer != nil {
-		maxPlayer.Lives--
+	if g.Options.Teams > 0 {
+		g.poolTeamLives(lives)
+	}
+	for id := range result.Numbers {
+		result.SubmittedAfter[id] = g.Players[id].SubmittedAt.Sub(g.roundStarted)
 	}
 
-	// Reset for next round
+	// Record the round in which players ran out of lives. Penalties that
+	// add up, such as a mismo and an extreme, never leave a player below 0.
 	for _, p := range g.Players {
-		p.Number = nil
-		p.HasSubmitted = false
+		if p.Lives < 0 {
+			p.Lives = 0
+		}
+		if p.Lives == 0 && p.EliminatedRound == 0 {
+			p.EliminatedRound = g.Round
+			result.Eliminated = append(result.Eliminated, p.ID)
+		}
 	}
+	sort.Strings(result.LostLife)
+	sort.Strings(result.Mismo)
+	sort.Strings(result.Eliminated)
+	g.LastRound = result
 
 	// Check if game is finished
-	activePlayers := 0
+	if g.sidesLeft() <= 1 {
+		g.State = Finished
+	} else {
+		g.State = RoundEnd
+	}
+}
+
+// living returns the players who still have lives, in seat order.
+func (g *Game) living() []*Player {
+	var players []*Player
 	for _, p := range g.Players {
 		if p.Lives > 0 {
-			activePlayers++
+			players = append(players, p)
 		}
 	}
-	if activePlayers <= 1 {
-		g.State = Finished
+	sort.Slice(players, func(i, j int) bool { return players[i].Seat < players[j].Seat })
+	return players
+}
+
+// submitted returns the living players who submitted a number, in seat
+// order, and records their numbers in the result.
+func (g *Game) submitted(result *RoundResult) []*Player {
+	var players []*Player
+	for _, p := range g.living() {
+		if p.Number != nil {
+			players = append(players, p)
+			result.Numbers[p.ID] = *p.Number
+		}
+	}
+	return players
+}
+
+// chargeMissed takes a life from every player who was in the round, given
+// by their lives before it, but submitted no number. Without this, letting
+// the round time out would be the safest play.
+func (g *Game) chargeMissed(result *RoundResult, before map[string]int) {
+	for _, p := range g.seated() {
+		if _, inRound := before[p.ID]; !inRound || p.Number != nil {
+			continue
+		}
+		p.Lives--
+		result.Missed = append(result.Missed, p.ID)
+		result.LostLife = append(result.LostLife, p.ID)
+	}
+}
+
+// evaluateNormal applies the standard rules: players in a mismo and on
+// the lowest and highest numbers are penalised, with ties treated as the
+// game's tie rules say.
+func (g *Game) evaluateNormal(result *RoundResult, players []*Player) {
+	penalties := g.Options.Ties.Apply(picksOf(players))
+	for _, id := range penalties.Mismo {
+		if g.Options.Ties.Penalty == TieEliminate {
+			g.Players[id].Lives = 0
+		} else {
+			g.Players[id].Lives--
+		}
+	}
+	for _, id := range penalties.Extremes {
+		g.Players[id].Lives--
+	}
+	result.Mismo = append(result.Mismo, penalties.Mismo...)
+	result.LostLife = append(result.LostLife, penalties.Extremes...)
+	result.LostOnTime = penalties.LostOnTime
+	result.Min, result.Max = penalties.Min, penalties.Max
+}
+
+// extremes returns the lowest and highest of the players' numbers, or nils
+// when there are none.
+func extremes(players []*Player) (min, max *Number) {
+	for _, p := range players {
+		if min == nil || p.Number.Cmp(*min) < 0 {
+			min = p.Number
+		}
+		if max == nil || p.Number.Cmp(*max) > 0 {
+			max = p.Number
+		}
+	}
+	return min, max
+}
+
+// Snapshot copies the game's state, with players in joining order.
+func (g *Game) Snapshot() Snapshot {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	s := Snapshot{
+		ID:        g.ID,
+		State:     g.State,
+		Phase:     g.Phase,
+		Round:     g.Round,
+		Options:   g.Options,
+		Range:     g.Range,
+		Players:   make([]Player, 0, len(g.Players)),
+		LastRound: g.LastRound,
+		Deadline:  g.deadline(),
+		Standings: g.standings(),
+	}
+	for _, p := range g.Players {
+		s.Players = append(s.Players, *p)
 	}
+	sort.Slice(s.Players, func(i, j int) bool { return s.Players[i].Seat < s.Players[j].Seat })
+	return s
 }
@@ -0,0 +1,159 @@
+package game
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// startGame starts a game with the given players, seated in the order
+// given; the first is the host.
+func startGame(t *testing.T, opts Options, ids ...string) *Game {
+	t.Helper()
+	g := NewGame("test", opts)
+	for _, id := range ids {
+		if err := g.AddPlayer(NewPlayer(id, id, false)); err != nil {
+			t.Fatalf("AddPlayer(%s): %v", id, err)
+		}
+	}
+	if err := g.Start(ids[0]); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	return g
+}
+
+// submitAll submits a number for each player, in the order of their IDs.
+func submitAll(t *testing.T, g *Game, numbers map[string]uint64) {
+	t.Helper()
+	for _, id := range sortedIDs(numbers) {
+		if err := g.SubmitNumber(id, NewNumber(numbers[id])); err != nil {
+			t.Fatalf("SubmitNumber(%s): %v", id, err)
+		}
+	}
+}
+
+func sortedIDs(numbers map[string]uint64) []string {
+	ids := make([]string, 0, len(numbers))
+	for id := range numbers {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// playRound starts a game with a player for each number, in the order of
+// their IDs, and plays its first round.
+func playRound(t *testing.T, opts Options, numbers map[string]uint64) *Game {
+	t.Helper()
+	g := startGame(t, opts, sortedIDs(numbers)...)
+	submitAll(t, g, numbers)
+	if g.State == Playing {
+		t.Fatal("round was not evaluated")
+	}
+	return g
+}
+
+// lives returns every player's lives by ID.
+func lives(g *Game) map[string]int {
+	out := make(map[string]int)
+	for id, p := range g.Players {
+		out[id] = p.Lives
+	}
+	return out
+}
+
+func TestEvaluateRoundChargesMissedPlayers(t *testing.T) {
+	tests := []struct {
+		name    string
+		lockIn  bool
+		numbers map[string]uint64 // submitted before the round times out
+		want    map[string]int
+		missed  []string
+	}{
+		{
+			name:    "a player who submitted nothing loses a life",
+			numbers: map[string]uint64{"a": 1, "b": 5, "c": 9},
+			want:    map[string]int{"a": 2, "b": 3, "c": 2, "d": 2},
+			missed:  []string{"d"},
+		},
+		{
+			name:    "an unlocked number still counts",
+			lockIn:  true,
+			numbers: map[string]uint64{"a": 1, "b": 5, "c": 9, "d": 6},
+			want:    map[string]int{"a": 2, "b": 3, "c": 2, "d": 3},
+		},
+		{
+			name:   "nobody submitting costs everyone a life",
+			want:   map[string]int{"a": 2, "b": 2, "c": 2, "d": 2},
+			missed: []string{"a", "b", "c", "d"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.Lives = 3
+			opts.RoundSeconds = 30
+			opts.LockIn = tt.lockIn
+			g := startGame(t, opts, "a", "b", "c", "d")
+			submitAll(t, g, tt.numbers)
+
+			if !g.EvaluateRound(1) {
+				t.Fatal("EvaluateRound(1) = false, want true")
+			}
+			if got := lives(g); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lives = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(g.LastRound.Missed, tt.missed) {
+				t.Errorf("Missed = %v, want %v", g.LastRound.Missed, tt.missed)
+			}
+		})
+	}
+}
+
+func TestEvaluateRoundIgnoresOtherRounds(t *testing.T) {
+	opts := DefaultOptions()
+	opts.RoundSeconds = 30
+	g := startGame(t, opts, "a", "b", "c")
+	if g.EvaluateRound(2) {
+		t.Error("EvaluateRound(2) evaluated round 1")
+	}
+	submitAll(t, g, map[string]uint64{"a": 1, "b": 5, "c": 9})
+	if g.EvaluateRound(1) {
+		t.Error("EvaluateRound(1) evaluated a round that was already over")
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(*Options)
+		ok     bool
+	}{
+		{"default", func(o *Options) {}, true},
+		{"round timer", func(o *Options) { o.RoundSeconds = 30 }, true},
+		{"longest round timer", func(o *Options) { o.RoundSeconds = MaxRoundSeconds }, true},
+		{"negative round timer", func(o *Options) { o.RoundSeconds = -1 }, false},
+		{"round timer over the maximum", func(o *Options) { o.RoundSeconds = MaxRoundSeconds + 1 }, false},
+		{"round timer overflowing a duration", func(o *Options) { o.RoundSeconds = 1 << 62 }, false},
+		{"shrinking widths", func(o *Options) { o.ShrinkWidths = []Number{NewNumber(50), NewNumber(20), NewNumber(1)} }, true},
+		{"repeated width", func(o *Options) { o.ShrinkWidths = []Number{NewNumber(50), NewNumber(50)} }, true},
+		{"zero width", func(o *Options) { o.ShrinkWidths = []Number{NewNumber(0)} }, false},
+		{"zero last width", func(o *Options) { o.ShrinkWidths = []Number{NewNumber(50), NewNumber(0)} }, false},
+		{"growing width", func(o *Options) { o.ShrinkWidths = []Number{NewNumber(20), NewNumber(50)} }, false},
+		{"growing last width", func(o *Options) { o.ShrinkWidths = []Number{NewNumber(50), NewNumber(20), NewNumber(21)} }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			tt.change(&opts)
+			err := opts.Validate()
+			if ok := err == nil; ok != tt.ok {
+				t.Fatalf("Validate() = %v, want ok %v", err, tt.ok)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidOptions) {
+				t.Errorf("Validate() = %v, want ErrInvalidOptions", err)
+			}
+		})
+	}
+}
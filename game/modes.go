@@ -0,0 +1,73 @@
+package game
+
+// Mode is the rule a normal round is decided by. The endgame, when one is
+// set, replaces it in the last rounds.
+type Mode string
+
+const (
+	// ModeClassic penalises the minimum, the maximum and mismos.
+	ModeClassic Mode = ""
+	// ModeLowestUnique makes the lowest number picked by exactly one player
+	// win the round; every other living player loses a life, or, with
+	// WinnerGainsLife, the winner gains one instead. Nothing happens when no
+	// number is unique.
+	ModeLowestUnique Mode = "lowestUnique"
+	// ModeBeautyContest sets a goal of p times the mean of the numbers; the
+	// players furthest from it lose a life, and mismos are penalised as in
+	// classic rounds.
+	ModeBeautyContest Mode = "beautyContest"
+)
+
+func (m Mode) valid() bool {
+	switch m {
+	case ModeClassic, ModeLowestUnique, ModeBeautyContest:
+		return true
+	}
+	return false
+}
+
+// evaluateLowestUnique applies ModeLowestUnique to the submitted numbers.
+func (g *Game) evaluateLowestUnique(result *RoundResult, players []*Player) {
+	result.Min, result.Max = extremes(players)
+	counts := make(map[string]int) // by decimal string
+	for _, p := range players {
+		counts[p.Number.String()]++
+	}
+
+	var winner *Player
+	for _, p := range players {
+		if counts[p.Number.String()] != 1 {
+			continue
+		}
+		if winner == nil || p.Number.Cmp(*winner.Number) < 0 {
+			winner = p
+		}
+	}
+	if winner == nil {
+		return
+	}
+
+	result.Winner = winner.ID
+	if !g.Options.WinnerGainsLife {
+		for _, p := range g.living() {
+			if p != winner {
+				p.Lives--
+				result.LostLife = append(result.LostLife, p.ID)
+			}
+		}
+		return
+	}
+
+	// Lives only grow, so the game is won by the first player to double
+	// their starting lives.
+	winner.Lives++
+	result.GainedLife = append(result.GainedLife, winner.ID)
+	if winner.Lives < 2*g.Options.Lives {
+		return
+	}
+	for _, p := range g.living() {
+		if p != winner {
+			p.Lives = 0
+		}
+	}
+}
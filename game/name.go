@@ -0,0 +1,124 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+var (
+	ErrEmptyName      = errors.New("name cannot be empty")
+	ErrNameTooLong    = errors.New("name is too long")
+	ErrNameNotAllowed = errors.New("name is not allowed")
+)
+
+// maxCombiningMarks caps the accents stacked on one character.
+const maxCombiningMarks = 2
+
+// NameRules configure how player names are validated.
+type NameRules struct {
+	// MaxLength is the maximum number of characters after normalisation.
+	MaxLength int
+	// Blocked lists words that may not appear in a name, compared
+	// case-insensitively and ignoring spacing and punctuation. Empty
+	// entries are ignored; an empty list disables the filter.
+	Blocked []string
+}
+
+// DefaultNameRules are the rules used when a server configures nothing else.
+var DefaultNameRules = NameRules{MaxLength: 24}
+
+// NormalizeName cleans up a name: it is brought to Unicode NFKC form, so
+// that composed and decomposed accents and compatibility variants such as
+// full-width letters compare equal, invalid UTF-8, control and invisible
+// formatting characters (including bidi overrides) are removed, stacked
+// combining marks are capped, and runs of whitespace become a single space.
+func NormalizeName(name string) string {
+	var b strings.Builder
+	space := false
+	marks := 0
+	for _, r := range norm.NFKC.String(strings.ToValidUTF8(name, "")) {
+		switch {
+		case unicode.IsSpace(r):
+			space = b.Len() > 0
+			continue
+		case unicode.IsControl(r), unicode.Is(unicode.Cf, r):
+			continue
+		case unicode.Is(unicode.Mn, r):
+			if marks >= maxCombiningMarks {
+				continue
+			}
+			marks++
+		default:
+			marks = 0
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+	// Removing characters may have left a letter next to its accent.
+	return norm.NFC.String(b.String())
+}
+
+// Validate normalises a name and checks it against the rules.
+func (rules NameRules) Validate(name string) (string, error) {
+	name = NormalizeName(name)
+	if name == "" {
+		return "", ErrEmptyName
+	}
+	if rules.MaxLength > 0 && utf8.RuneCountInString(name) > rules.MaxLength {
+		return "", ErrNameTooLong
+	}
+
+	folded := foldName(name)
+	for _, word := range rules.Blocked {
+		if word = foldName(word); word != "" && strings.Contains(folded, word) {
+			return "", ErrNameNotAllowed
+		}
+	}
+	return name, nil
+}
+
+// foldName keeps only the lower-cased letters and digits of a name, so that
+// "B a_D" and "bad" compare equal.
+func foldName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(norm.NFKC.String(name)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// UniqueName returns name, or name with a " (2)", " (3)", ... suffix when
+// taken reports it is already used in the game. The name is shortened to
+// keep a suffixed name within MaxLength.
+func (rules NameRules) UniqueName(name string, taken func(string) bool) string {
+	candidate := name
+	for i := 2; taken(candidate); i++ {
+		suffix := fmt.Sprintf(" (%d)", i)
+		candidate = truncateName(name, rules.MaxLength-utf8.RuneCountInString(suffix)) + suffix
+	}
+	return candidate
+}
+
+// truncateName cuts a name to at most n characters, without leaving a
+// trailing space or an accent split from its letter.
+func truncateName(name string, n int) string {
+	if n <= 0 || utf8.RuneCountInString(name) <= n {
+		return name
+	}
+	runes := []rune(name)
+	cut := n
+	for cut > 0 && unicode.Is(unicode.Mn, runes[cut]) {
+		cut-- // drop the letter along with its accents
+	}
+	return strings.TrimRight(string(runes[:cut]), " ")
+}
@@ -0,0 +1,99 @@
+package game
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "Ana", "Ana"},
+		{"empty", "", ""},
+		{"only spaces", " \t\n ", ""},
+		{"surrounding and inner spacing", "  Ana \t  María  ", "Ana María"},
+		{"decomposed accent", "Jose\u0301", "Jos\u00e9"},
+		{"full-width letters", "Ａｎａ", "Ana"},
+		{"ligature", "ﬁona", "fiona"},
+		{"control characters", "A\x00n\x1ba", "Ana"},
+		{"bidi override", "\u202eanA", "anA"},
+		{"zero-width space", "A\u200bna", "Ana"},
+		{"only invisible", "\u200b\u200d\u2060", ""},
+		{"invalid UTF-8", "An\xffa", "Ana"},
+		{"stacked marks are capped", "a\u0301\u0302\u0303\u0304", "\u00e1\u0302\u0303"},
+		{"accent rejoined after a removed character", "e\u200b\u0301", "\u00e9"},
+		{"non-Latin script", "  Δημήτρης  ", "Δημήτρης"},
+		{"emoji", "🎲 Dice", "🎲 Dice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeName(tt.in); got != tt.want {
+				t.Errorf("NormalizeName(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	rules := NameRules{MaxLength: 5, Blocked: []string{"bad", ""}}
+	tests := []struct {
+		in   string
+		want string
+		err  error
+	}{
+		{"Ana", "Ana", nil},
+		{"", "", ErrEmptyName},
+		{"\u200b", "", ErrEmptyName},
+		{"Ñandú", "Ñandú", nil}, // five characters, more bytes
+		{"Ñandús", "", ErrNameTooLong},
+		{"B a_D", "", ErrNameNotAllowed},
+		{"ＢＡＤ", "", ErrNameNotAllowed},
+		{"Bart", "Bart", nil},
+	}
+	for _, tt := range tests {
+		got, err := rules.Validate(tt.in)
+		if got != tt.want || !errors.Is(err, tt.err) {
+			t.Errorf("Validate(%q) = %q, %v, want %q, %v", tt.in, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestUniqueName(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules NameRules
+		in    string
+		taken []string
+		want  string
+	}{
+		{"free", DefaultNameRules, "Ana", nil, "Ana"},
+		{"taken", DefaultNameRules, "Ana", []string{"ana"}, "Ana (2)"},
+		{"suffixes taken too", DefaultNameRules, "Ana", []string{"ana", "ana (2)", "ana (3)"}, "Ana (4)"},
+		{"unlimited length", NameRules{}, "Ana", []string{"ana"}, "Ana (2)"},
+		{"shortened to fit", NameRules{MaxLength: 8}, "Ricardo", []string{"ricardo"}, "Rica (2)"},
+		{"no trailing space", NameRules{MaxLength: 8}, "Ana María", []string{"ana maría"}, "Ana (2)"},
+		{"accent kept with its letter", NameRules{MaxLength: 8}, "Jose\u0301e", []string{"jose\u0301e"}, "Jos (2)"},
+		{"two-digit suffix", NameRules{MaxLength: 8}, "Ricardo", []string{
+			"ricardo", "rica (2)", "rica (3)", "rica (4)", "rica (5)", "rica (6)", "rica (7)", "rica (8)", "rica (9)",
+		}, "Ric (10)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.rules.UniqueName(tt.in, func(name string) bool {
+				for _, taken := range tt.taken {
+					if strings.EqualFold(taken, name) {
+						return true
+					}
+				}
+				return false
+			})
+			if got != tt.want {
+				t.Errorf("UniqueName(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
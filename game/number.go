@@ -0,0 +1,126 @@
+package game
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidNumber    = errors.New("number must be a non-negative whole number")
+	ErrNumberOutOfRange = errors.New("number is out of range")
+)
+
+// MaxNumberDigits caps the length of any number, including option bounds,
+// so that arithmetic on submissions stays cheap.
+const MaxNumberDigits = 100
+
+// Number is a submitted number: a non-negative integer of any size. The
+// zero value is 0. Numbers are encoded in JSON as decimal strings so that
+// clients whose numbers are doubles do not round them, and decoded from
+// either strings or JSON numbers without going through float64.
+type Number struct {
+	v *big.Int // nil means 0; never modified once set
+}
+
+func NewNumber(n uint64) Number {
+	return Number{v: new(big.Int).SetUint64(n)}
+}
+
+// ParseNumber parses a decimal number. Signs, fractions, exponents and
+// numbers longer than MaxNumberDigits are rejected with ErrInvalidNumber or
+// ErrNumberOutOfRange.
+func ParseNumber(s string) (Number, error) {
+	if s == "" {
+		return Number{}, ErrInvalidNumber
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return Number{}, ErrInvalidNumber
+		}
+	}
+	if len(strings.TrimLeft(s, "0")) > MaxNumberDigits {
+		return Number{}, ErrNumberOutOfRange
+	}
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return Number{}, ErrInvalidNumber
+	}
+	return Number{v: v}, nil
+}
+
+// MaxUint64 is the largest number most clients can hold exactly as an
+// integer, and the default upper bound.
+var MaxUint64 = NewNumber(math.MaxUint64)
+
+func (n Number) int() *big.Int {
+	if n.v == nil {
+		return new(big.Int)
+	}
+	return n.v
+}
+
+// Int returns the number as a big.Int the caller may modify.
+func (n Number) Int() *big.Int {
+	return new(big.Int).Set(n.int())
+}
+
+// Cmp compares two numbers, returning -1, 0 or +1.
+func (n Number) Cmp(o Number) int {
+	return n.int().Cmp(o.int())
+}
+
+// Uint64 returns the number if it fits in a uint64.
+func (n Number) Uint64() (uint64, bool) {
+	v := n.int()
+	return v.Uint64(), v.IsUint64()
+}
+
+func (n Number) String() string {
+	return n.int().String()
+}
+
+func (n Number) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + n.String() + `"`), nil
+}
+
+func (n *Number) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) >= 2 && data[0] == '"' && data[len(data)-1] == '"' {
+		data = data[1 : len(data)-1]
+	}
+	parsed, err := ParseNumber(string(data))
+	if err != nil {
+		return err
+	}
+	*n = parsed
+	return nil
+}
+
+// CheckNumber reports whether a number lies within the game's bounds.
+func (o Options) CheckNumber(n Number) error {
+	return o.bounds().Check(n)
+}
+
+// RangeError is returned for a number outside the allowed range. It matches
+// ErrNumberOutOfRange with errors.Is.
+type RangeError struct {
+	Min, Max Number
+	// Round is set when the range applies to a single round.
+	Round int
+}
+
+func (e *RangeError) Error() string {
+	msg := "number must be between " + e.Min.String() + " and " + e.Max.String()
+	if e.Round > 0 {
+		msg += " in round " + strconv.Itoa(e.Round)
+	}
+	return msg
+}
+
+func (e *RangeError) Is(target error) bool {
+	return target == ErrNumberOutOfRange
+}
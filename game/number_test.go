@@ -0,0 +1,112 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseNumber(t *testing.T) {
+	hundred := "1" + strings.Repeat("0", MaxNumberDigits-1)
+	tests := []struct {
+		in   string
+		want string
+		err  error
+	}{
+		{"0", "0", nil},
+		{"42", "42", nil},
+		{"007", "7", nil},
+		{"000", "0", nil},
+		{"18446744073709551615", "18446744073709551615", nil},
+		{"18446744073709551616", "18446744073709551616", nil}, // past uint64
+		{hundred, hundred, nil},
+		{"000" + hundred, hundred, nil}, // leading zeros do not count
+		{hundred + "0", "", ErrNumberOutOfRange},
+		{"", "", ErrInvalidNumber},
+		{"-1", "", ErrInvalidNumber},
+		{"+1", "", ErrInvalidNumber},
+		{"1.0", "", ErrInvalidNumber},
+		{"1e3", "", ErrInvalidNumber},
+		{" 1", "", ErrInvalidNumber},
+		{"0x10", "", ErrInvalidNumber},
+		{"1_000", "", ErrInvalidNumber},
+		{"٣", "", ErrInvalidNumber}, // non-ASCII digit
+	}
+	for _, tt := range tests {
+		got, err := ParseNumber(tt.in)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("ParseNumber(%q) error = %v, want %v", tt.in, err, tt.err)
+			continue
+		}
+		if err == nil && got.String() != tt.want {
+			t.Errorf("ParseNumber(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNumberJSON(t *testing.T) {
+	huge := strings.Repeat("9", MaxNumberDigits)
+	tests := []struct {
+		name string
+		in   string
+		want string
+		err  error
+	}{
+		{"quoted", `"42"`, "42", nil},
+		{"bare", `42`, "42", nil},
+		{"quoted with leading zeros", `"007"`, "7", nil},
+		{"bare zero", `0`, "0", nil},
+		{"quoted beyond float64 precision", `"9007199254740993"`, "9007199254740993", nil},
+		{"bare beyond float64 precision", `9007199254740993`, "9007199254740993", nil},
+		{"huge quoted", `"` + huge + `"`, huge, nil},
+		{"huge bare", huge, huge, nil},
+		{"too long", `"` + huge + `9"`, "", ErrNumberOutOfRange},
+		{"empty string", `""`, "", ErrInvalidNumber},
+		{"negative", `-1`, "", ErrInvalidNumber},
+		{"fraction", `1.5`, "", ErrInvalidNumber},
+		{"exponent", `1e3`, "", ErrInvalidNumber},
+		{"quoted with spaces", `" 42 "`, "", ErrInvalidNumber},
+		{"null", `null`, "", ErrInvalidNumber},
+		{"boolean", `true`, "", ErrInvalidNumber},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var n Number
+			err := n.UnmarshalJSON([]byte(tt.in))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error = %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if n.String() != tt.want {
+				t.Errorf("decoded %s, want %s", n, tt.want)
+			}
+			data, err := json.Marshal(n)
+			if err != nil || string(data) != `"`+tt.want+`"` {
+				t.Errorf("encoded as %s, %v, want the quoted decimal", data, err)
+			}
+		})
+	}
+}
+
+func TestNumberJSONField(t *testing.T) {
+	var msg struct {
+		Number  *Number `json:"number"`
+		Numbers []Number
+	}
+	if err := json.Unmarshal([]byte(`{"number": null, "Numbers": ["1", 2, "003"]}`), &msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Number != nil {
+		t.Errorf("null decoded as %s", msg.Number)
+	}
+	if len(msg.Numbers) != 3 || msg.Numbers[0].String() != "1" || msg.Numbers[1].String() != "2" || msg.Numbers[2].String() != "3" {
+		t.Errorf("numbers = %v", msg.Numbers)
+	}
+	var zero Number
+	if data, _ := json.Marshal(zero); string(data) != `"0"` {
+		t.Errorf("zero value encoded as %s", data)
+	}
+}
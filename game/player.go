@@ -1,12 +1,30 @@
 package game
 
+import "time"
+
 type Player struct {
 	ID           string  `json:"id"`
 	Name         string  `json:"name"`
 	Lives        int     `json:"lives"`
-	Number       *uint64 `json:"number"`
+	Number       *Number `json:"number"`
 	IsHost       bool    `json:"isHost"`
 	HasSubmitted bool    `json:"hasSubmitted"`
+	// Team is the player's team in a team game, from 1, or 0.
+	Team int `json:"team,omitempty"`
+	// Cards is the player's hand, and Play the card they played this round.
+	Cards []Card    `json:"cards,omitempty"`
+	Play  *CardPlay `json:"play,omitempty"`
+	// Wagered is set once the player has staked a life on this round.
+	Wagered bool `json:"wagered,omitempty"`
+	// Locked is set once the player's number can no longer change.
+	Locked bool `json:"locked"`
+	// EliminatedRound is the round in which the player ran out of lives,
+	// or 0 while they are still in the game.
+	EliminatedRound int `json:"eliminatedRound,omitempty"`
+	// Seat is the player's position in joining order.
+	Seat int `json:"-"`
+	// SubmittedAt is when the server received the player's number.
+	SubmittedAt time.Time `json:"-"`
 }
 
 func NewPlayer(id, name string, isHost bool) *Player {
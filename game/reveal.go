@@ -0,0 +1,103 @@
+package game
+
+import "sort"
+
+// Reveal says how much of a round's numbers players see once it has been
+// evaluated. A player always sees their own number.
+type Reveal string
+
+const (
+	// RevealFull shows every number with the player who picked it.
+	RevealFull Reveal = ""
+	// RevealExtremes only shows the numbers that decided the round: the
+	// minimum, the maximum, mismos and a round's winner.
+	RevealExtremes Reveal = "extremes"
+	// RevealAnonymous shows every number, but not who picked it. Nor does
+	// the round result say who was in a mismo, lost or won a life, or won
+	// or lost a wager, besides the viewer.
+	RevealAnonymous Reveal = "anonymous"
+)
+
+func (r Reveal) valid() bool {
+	switch r {
+	case RevealFull, RevealExtremes, RevealAnonymous:
+		return true
+	}
+	return false
+}
+
+// Project returns the round result as a viewer may see it under a reveal
+// policy. The viewer is a player ID, or empty for a spectator. Under
+// anything but RevealFull a peek stays private to the player who played it.
+func (r *RoundResult) Project(policy Reveal, viewer string) *RoundResult {
+	if r == nil || policy == RevealFull {
+		return r
+	}
+
+	out := *r
+	out.Numbers = make(map[string]Number)
+	switch policy {
+	case RevealExtremes:
+		shown := make(map[string]bool)
+		for _, id := range r.Mismo {
+			shown[id] = true
+		}
+		shown[r.Winner] = true
+		for id, n := range r.Numbers {
+			if shown[id] || (r.Min != nil && n.Cmp(*r.Min) == 0) || (r.Max != nil && n.Cmp(*r.Max) == 0) {
+				out.Numbers[id] = n
+			}
+		}
+	case RevealAnonymous:
+		out.Revealed = make([]Number, 0, len(r.Numbers))
+		for _, n := range r.Numbers {
+			out.Revealed = append(out.Revealed, n)
+		}
+		sort.Slice(out.Revealed, func(i, j int) bool { return out.Revealed[i].Cmp(out.Revealed[j]) < 0 })
+		out.anonymise(viewer)
+	}
+	if n, ok := r.Numbers[viewer]; ok {
+		out.Numbers[viewer] = n
+	}
+
+	out.Cards = make([]CardPlay, len(r.Cards))
+	for i, play := range r.Cards {
+		if play.PlayerID != viewer {
+			play.Peeked = nil
+		}
+		out.Cards[i] = play
+	}
+	return &out
+}
+
+// anonymise drops every player but the viewer from the lists that would
+// tell who picked which number.
+func (r *RoundResult) anonymise(viewer string) {
+	r.Mismo = onlyViewer(r.Mismo, viewer)
+	r.LostLife = onlyViewer(r.LostLife, viewer)
+	r.GainedLife = onlyViewer(r.GainedLife, viewer)
+	r.Shielded = onlyViewer(r.Shielded, viewer)
+	r.LostOnTime = onlyViewer(r.LostOnTime, viewer)
+	if r.Winner != viewer {
+		r.Winner = ""
+	}
+	var wagers []Wager
+	for _, w := range r.Wagers {
+		if w.PlayerID == viewer {
+			wagers = append(wagers, w)
+		}
+	}
+	r.Wagers = wagers
+}
+
+// onlyViewer returns the entries of ids naming the viewer. Lists that are
+// always sent stay non-nil.
+func onlyViewer(ids []string, viewer string) []string {
+	out := []string{}
+	for _, id := range ids {
+		if id == viewer && viewer != "" {
+			out = append(out, id)
+		}
+	}
+	return out
+}
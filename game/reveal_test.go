@@ -0,0 +1,117 @@
+package game
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestRevealExtremesShowsWhatDecidedTheRound(t *testing.T) {
+	beauty := DefaultOptions()
+	beauty.Mode = ModeBeautyContest
+	lowestUnique := DefaultOptions()
+	lowestUnique.Mode = ModeLowestUnique
+	parity := DefaultOptions()
+	parity.Endgame = EndgameParity
+	parity.EndgamePlayers = 3
+
+	tests := []struct {
+		name    string
+		opts    Options
+		numbers map[string]uint64
+		shown   []string // players whose numbers are revealed
+	}{
+		{"classic", DefaultOptions(), map[string]uint64{"a": 1, "b": 5, "c": 9}, []string{"a", "c"}},
+		{"classic mismo", DefaultOptions(), map[string]uint64{"a": 1, "b": 5, "c": 5, "d": 9}, []string{"a", "b", "c", "d"}},
+		{"beauty contest", beauty, map[string]uint64{"a": 0, "b": 40, "c": 50, "d": 100}, []string{"a", "d"}},
+		{"lowest unique", lowestUnique, map[string]uint64{"a": 3, "b": 3, "c": 5, "d": 9}, []string{"a", "b", "c", "d"}},
+		{"endgame", parity, map[string]uint64{"a": 1, "b": 5, "c": 9}, []string{"a", "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := playRound(t, tt.opts, tt.numbers)
+			if g.LastRound.Min == nil || g.LastRound.Max == nil {
+				t.Fatalf("round has no extremes: min %v, max %v", g.LastRound.Min, g.LastRound.Max)
+			}
+			for _, id := range g.LastRound.LostLife {
+				if _, ok := g.LastRound.Project(RevealExtremes, "").Numbers[id]; !ok {
+					t.Errorf("the number of %s, who lost a life, is hidden", id)
+				}
+			}
+
+			var shown []string
+			for id := range g.LastRound.Project(RevealExtremes, "").Numbers {
+				shown = append(shown, id)
+			}
+			sort.Strings(shown)
+			if !reflect.DeepEqual(shown, tt.shown) {
+				t.Errorf("revealed the numbers of %v, want %v", shown, tt.shown)
+			}
+		})
+	}
+}
+
+func TestRevealAnonymousHidesWhoPickedWhat(t *testing.T) {
+	result := &RoundResult{
+		Numbers:    map[string]Number{"a": NewNumber(1), "b": NewNumber(5), "c": NewNumber(5), "d": NewNumber(9)},
+		Min:        number(1),
+		Max:        number(9),
+		Mismo:      []string{"b", "c"},
+		LostLife:   []string{"a", "d", "d"},
+		Eliminated: []string{},
+		Shielded:   []string{"a"},
+		GainedLife: []string{"c"},
+		Wagers:     []Wager{{PlayerID: "a", Won: false}, {PlayerID: "d", Won: false}},
+		Winner:     "c",
+	}
+
+	tests := []struct {
+		viewer string
+		want   RoundResult
+	}{
+		{"", RoundResult{Mismo: []string{}, LostLife: []string{}, GainedLife: []string{}, Shielded: []string{}}},
+		{"b", RoundResult{Mismo: []string{"b"}, LostLife: []string{}, GainedLife: []string{}, Shielded: []string{}}},
+		{"d", RoundResult{Mismo: []string{}, LostLife: []string{"d", "d"}, GainedLife: []string{}, Shielded: []string{},
+			Wagers: []Wager{{PlayerID: "d"}}}},
+		{"c", RoundResult{Mismo: []string{"c"}, LostLife: []string{}, GainedLife: []string{"c"}, Shielded: []string{},
+			Winner: "c"}},
+	}
+	for _, tt := range tests {
+		t.Run("viewer "+tt.viewer, func(t *testing.T) {
+			got := result.Project(RevealAnonymous, tt.viewer)
+
+			wantNumbers := map[string]Number{}
+			if n, ok := result.Numbers[tt.viewer]; ok {
+				wantNumbers[tt.viewer] = n
+			}
+			if !reflect.DeepEqual(got.Numbers, wantNumbers) {
+				t.Errorf("Numbers = %v, want %v", got.Numbers, wantNumbers)
+			}
+			if want := []Number{NewNumber(1), NewNumber(5), NewNumber(5), NewNumber(9)}; !reflect.DeepEqual(got.Revealed, want) {
+				t.Errorf("Revealed = %v, want %v", got.Revealed, want)
+			}
+			if !reflect.DeepEqual(got.Mismo, tt.want.Mismo) {
+				t.Errorf("Mismo = %v, want %v", got.Mismo, tt.want.Mismo)
+			}
+			if !reflect.DeepEqual(got.LostLife, tt.want.LostLife) {
+				t.Errorf("LostLife = %v, want %v", got.LostLife, tt.want.LostLife)
+			}
+			if !reflect.DeepEqual(got.GainedLife, tt.want.GainedLife) {
+				t.Errorf("GainedLife = %v, want %v", got.GainedLife, tt.want.GainedLife)
+			}
+			if !reflect.DeepEqual(got.Shielded, tt.want.Shielded) {
+				t.Errorf("Shielded = %v, want %v", got.Shielded, tt.want.Shielded)
+			}
+			if !reflect.DeepEqual(got.Wagers, tt.want.Wagers) {
+				t.Errorf("Wagers = %v, want %v", got.Wagers, tt.want.Wagers)
+			}
+			if got.Winner != tt.want.Winner {
+				t.Errorf("Winner = %q, want %q", got.Winner, tt.want.Winner)
+			}
+		})
+	}
+
+	if len(result.Mismo) != 2 || len(result.LostLife) != 3 {
+		t.Error("Project changed the original result")
+	}
+}
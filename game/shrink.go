@@ -0,0 +1,111 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+// Range is the span of numbers players may submit in a round, bounds
+// included.
+type Range struct {
+	Min Number `json:"min"`
+	Max Number `json:"max"`
+}
+
+// Check reports whether a number lies within the range.
+func (r Range) Check(n Number) error {
+	if n.Cmp(r.Min) < 0 || n.Cmp(r.Max) > 0 {
+		return &RangeError{Min: r.Min, Max: r.Max}
+	}
+	return nil
+}
+
+func (o Options) bounds() Range {
+	return Range{Min: o.MinNumber, Max: o.MaxNumber}
+}
+
+// validateShrinkWidths checks that the range only ever narrows: a zero
+// width would leave a single number to pick, and a growing one would undo
+// the previous rounds.
+func (o Options) validateShrinkWidths() error {
+	for i, w := range o.ShrinkWidths {
+		if w.int().Sign() == 0 {
+			return fmt.Errorf("%w: shrink width %d is zero", ErrInvalidOptions, i+1)
+		}
+		if i > 0 && w.Cmp(o.ShrinkWidths[i-1]) > 0 {
+			return fmt.Errorf("%w: shrink width %d is wider than the one before", ErrInvalidOptions, i+1)
+		}
+	}
+	return nil
+}
+
+// checkNumber reports whether a number lies within the current round's
+// range. A shrinking range names the round in its error.
+func (g *Game) checkNumber(n Number) error {
+	err := g.Range.Check(n)
+	var rangeErr *RangeError
+	if len(g.Options.ShrinkWidths) > 0 && errors.As(err, &rangeErr) {
+		rangeErr.Round = g.Round
+	}
+	return err
+}
+
+// roundRange works out the range for the current round. Without shrink
+// widths it is the whole of the options' bounds. Otherwise it is as wide as
+// the round's entry in ShrinkWidths, centred on the middle of the bounds or,
+// with ShrinkToMedian, on the median of the previous round, and moved back
+// inside the bounds where it would overhang them.
+func (g *Game) roundRange() Range {
+	bounds := g.Options.bounds()
+	widths := g.Options.ShrinkWidths
+	if len(widths) == 0 {
+		return bounds
+	}
+	i := g.Round - 1
+	if i >= len(widths) {
+		i = len(widths) - 1
+	}
+	width := widths[i].int()
+	min, max := bounds.Min.int(), bounds.Max.int()
+	if width.Cmp(new(big.Int).Sub(max, min)) >= 0 {
+		return bounds
+	}
+
+	var centre *big.Int
+	if g.Options.ShrinkToMedian && g.LastRound != nil && len(g.LastRound.Numbers) > 0 {
+		centre = median(g.LastRound.Numbers)
+	} else {
+		centre = new(big.Int).Add(min, max)
+		centre.Rsh(centre, 1)
+	}
+
+	lo := new(big.Int).Sub(centre, new(big.Int).Rsh(width, 1))
+	if lo.Cmp(min) < 0 {
+		lo.Set(min)
+	}
+	hi := new(big.Int).Add(lo, width)
+	if hi.Cmp(max) > 0 {
+		hi.Set(max)
+		lo.Sub(hi, width)
+	}
+	return Range{Min: Number{v: lo}, Max: Number{v: hi}}
+}
+
+// median returns the middle number, rounding down between the two middle
+// numbers of an even count.
+func median(numbers map[string]Number) *big.Int {
+	sorted := make([]Number, 0, len(numbers))
+	for _, n := range numbers {
+		sorted = append(sorted, n)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Cmp(sorted[j]) < 0 })
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return new(big.Int).Set(sorted[mid].int())
+	}
+	m := new(big.Int).Add(sorted[mid-1].int(), sorted[mid].int())
+	return m.Rsh(m, 1)
+}
@@ -0,0 +1,67 @@
+package game
+
+import "testing"
+
+func TestRoundRange(t *testing.T) {
+	widths := func(ws ...uint64) []Number {
+		out := make([]Number, len(ws))
+		for i, w := range ws {
+			out[i] = NewNumber(w)
+		}
+		return out
+	}
+	tests := []struct {
+		name     string
+		widths   []Number
+		median   bool
+		round    int
+		last     map[string]uint64 // the previous round's numbers
+		min, max uint64
+	}{
+		{name: "no shrinking", round: 3, min: 0, max: 100},
+		{name: "first step centred", widths: widths(50, 20, 10), round: 1, min: 25, max: 75},
+		{name: "second step", widths: widths(50, 20, 10), round: 2, min: 40, max: 60},
+		{name: "last step", widths: widths(50, 20, 10), round: 3, min: 45, max: 55},
+		{name: "last step holds after the list", widths: widths(50, 20, 10), round: 9, min: 45, max: 55},
+		{name: "odd width", widths: widths(5), round: 1, min: 48, max: 53},
+		{name: "width of the whole span", widths: widths(100), round: 1, min: 0, max: 100},
+		{name: "width beyond the span", widths: widths(1000), round: 1, min: 0, max: 100},
+		{
+			name: "centred on the median", widths: widths(50, 20), median: true, round: 2,
+			last: map[string]uint64{"a": 30, "b": 35, "c": 90}, min: 25, max: 45,
+		},
+		{
+			name: "median of an even count rounds down", widths: widths(50, 20), median: true, round: 2,
+			last: map[string]uint64{"a": 30, "b": 35, "c": 40, "d": 90}, min: 27, max: 47,
+		},
+		{
+			name: "median near the minimum is moved inside", widths: widths(50, 20), median: true, round: 2,
+			last: map[string]uint64{"a": 1, "b": 2, "c": 3}, min: 0, max: 20,
+		},
+		{
+			name: "median near the maximum is moved inside", widths: widths(50, 20), median: true, round: 2,
+			last: map[string]uint64{"a": 97, "b": 99, "c": 100}, min: 80, max: 100,
+		},
+		{name: "median without a previous round", widths: widths(50, 20), median: true, round: 1, min: 25, max: 75},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.MaxNumber = NewNumber(100)
+			opts.ShrinkWidths = tt.widths
+			opts.ShrinkToMedian = tt.median
+			g := NewGame("test", opts)
+			g.Round = tt.round
+			if tt.last != nil {
+				g.LastRound = &RoundResult{Numbers: make(map[string]Number)}
+				for id, n := range tt.last {
+					g.LastRound.Numbers[id] = NewNumber(n)
+				}
+			}
+			got := g.roundRange()
+			if got.Min.Cmp(NewNumber(tt.min)) != 0 || got.Max.Cmp(NewNumber(tt.max)) != 0 {
+				t.Errorf("range = %s..%s, want %d..%d", got.Min, got.Max, tt.min, tt.max)
+			}
+		})
+	}
+}
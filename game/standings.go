@@ -0,0 +1,82 @@
+package game
+
+import "sort"
+
+// Standing is a player's final placement. Players eliminated in the same
+// round share a place, and the next place skips accordingly (1, 2, 2, 4).
+type Standing struct {
+	ID              string `json:"id,omitempty"`
+	Name            string `json:"name"`
+	Team            int    `json:"team,omitempty"`
+	Place           int    `json:"place"`
+	EliminatedRound int    `json:"eliminatedRound,omitempty"`
+}
+
+// Standings are the final result of a game. Winners holds the names of the
+// players in first place; when more than one player or team shares it, for
+// example when the last players are eliminated together, the game is a draw.
+type Standings struct {
+	Places  []Standing `json:"places"`
+	Winners []string   `json:"winners"`
+	Draw    bool       `json:"draw"`
+}
+
+// NewStandings ranks players by how long they lasted: players still alive
+// first, then the most recent eliminations. Within a place players are
+// listed by seat.
+func NewStandings(players []Player) *Standings {
+	ranked := append([]Player(nil), players...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i].EliminatedRound, ranked[j].EliminatedRound
+		if a != b {
+			// Survivors (round 0) first, then the latest eliminations.
+			return a == 0 || (b != 0 && a > b)
+		}
+		return ranked[i].Seat < ranked[j].Seat
+	})
+
+	s := &Standings{Places: make([]Standing, 0, len(ranked)), Winners: []string{}}
+	sides := 0
+	winningTeams := make(map[int]bool)
+	for i, p := range ranked {
+		place := i + 1
+		if i > 0 && p.EliminatedRound == ranked[i-1].EliminatedRound {
+			place = s.Places[i-1].Place
+		}
+		s.Places = append(s.Places, Standing{
+			ID:              p.ID,
+			Name:            p.Name,
+			Team:            p.Team,
+			Place:           place,
+			EliminatedRound: p.EliminatedRound,
+		})
+		if place == 1 {
+			s.Winners = append(s.Winners, p.Name)
+			if p.Team == 0 || !winningTeams[p.Team] {
+				sides++
+			}
+			winningTeams[p.Team] = true
+		}
+	}
+	s.Draw = sides != 1
+	return s
+}
+
+// Standings returns the final standings once the game is finished, and nil
+// before.
+func (g *Game) Standings() *Standings {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.standings()
+}
+
+func (g *Game) standings() *Standings {
+	if g.State != Finished {
+		return nil
+	}
+	players := make([]Player, 0, len(g.Players))
+	for _, p := range g.Players {
+		players = append(players, *p)
+	}
+	return NewStandings(players)
+}
@@ -0,0 +1,89 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewStandings(t *testing.T) {
+	// player is a seated player eliminated in the given round, 0 if alive.
+	player := func(id string, seat, team, eliminated int) Player {
+		return Player{ID: id, Name: id, Seat: seat, Team: team, EliminatedRound: eliminated}
+	}
+	tests := []struct {
+		name    string
+		players []Player
+		places  map[string]int
+		order   []string
+		winners []string
+		draw    bool
+	}{
+		{
+			name:    "one survivor",
+			players: []Player{player("a", 0, 0, 2), player("b", 1, 0, 0), player("c", 2, 0, 3)},
+			places:  map[string]int{"b": 1, "c": 2, "a": 3},
+			order:   []string{"b", "c", "a"},
+			winners: []string{"b"},
+		},
+		{
+			name:    "eliminated together share a place and skip the next",
+			players: []Player{player("a", 0, 0, 4), player("b", 1, 0, 2), player("c", 2, 0, 2), player("d", 3, 0, 1)},
+			places:  map[string]int{"a": 1, "b": 2, "c": 2, "d": 4},
+			order:   []string{"a", "b", "c", "d"},
+			winners: []string{"a"},
+		},
+		{
+			name:    "last players out together draw",
+			players: []Player{player("a", 0, 0, 5), player("b", 1, 0, 5), player("c", 2, 0, 1)},
+			places:  map[string]int{"a": 1, "b": 1, "c": 3},
+			order:   []string{"a", "b", "c"},
+			winners: []string{"a", "b"},
+			draw:    true,
+		},
+		{
+			name:    "shared place listed by seat",
+			players: []Player{player("c", 2, 0, 0), player("a", 0, 0, 1), player("b", 1, 0, 1)},
+			places:  map[string]int{"c": 1, "a": 2, "b": 2},
+			order:   []string{"c", "a", "b"},
+			winners: []string{"c"},
+		},
+		{
+			name:    "a winning team is not a draw",
+			players: []Player{player("a", 0, 1, 0), player("b", 1, 2, 3), player("c", 2, 1, 0), player("d", 3, 2, 3)},
+			places:  map[string]int{"a": 1, "c": 1, "b": 3, "d": 3},
+			order:   []string{"a", "c", "b", "d"},
+			winners: []string{"a", "c"},
+		},
+		{
+			name:    "teams out together draw",
+			players: []Player{player("a", 0, 1, 3), player("b", 1, 2, 3)},
+			places:  map[string]int{"a": 1, "b": 1},
+			order:   []string{"a", "b"},
+			winners: []string{"a", "b"},
+			draw:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewStandings(tt.players)
+			order := []string{}
+			places := make(map[string]int)
+			for _, st := range s.Places {
+				order = append(order, st.ID)
+				places[st.ID] = st.Place
+			}
+			if !reflect.DeepEqual(order, tt.order) {
+				t.Errorf("order = %v, want %v", order, tt.order)
+			}
+			if !reflect.DeepEqual(places, tt.places) {
+				t.Errorf("places = %v, want %v", places, tt.places)
+			}
+			if !reflect.DeepEqual(s.Winners, tt.winners) {
+				t.Errorf("winners = %v, want %v", s.Winners, tt.winners)
+			}
+			if s.Draw != tt.draw {
+				t.Errorf("draw = %v, want %v", s.Draw, tt.draw)
+			}
+		})
+	}
+}
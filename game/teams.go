@@ -0,0 +1,180 @@
+package game
+
+import (
+	"errors"
+	"sort"
+)
+
+var (
+	ErrNoTeams     = errors.New("game has no teams")
+	ErrInvalidTeam = errors.New("no such team")
+	ErrEmptyTeam   = errors.New("every team needs a player")
+)
+
+// In a team game every player belongs to one of Options.Teams teams,
+// numbered from 1. A team shares a single pool of lives, which every member
+// carries as their own Lives: each life a member loses comes out of the
+// pool, and the whole team is eliminated together when it runs dry.
+// Teammates who pick the same number are a team mismo, which costs the
+// team a life and takes their picks out of the round.
+
+// AssignTeam moves a player to a team before the game starts. Only the
+// host can assign teams.
+func (g *Game) AssignTeam(hostID, playerID string, team int) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err := g.checkTeams(hostID); err != nil {
+		return err
+	}
+	player, exists := g.Players[playerID]
+	if !exists {
+		return ErrPlayerNotFound
+	}
+	if team < 1 || team > g.Options.Teams {
+		return ErrInvalidTeam
+	}
+	player.Team = team
+	return nil
+}
+
+// BalanceTeams deals the players out to the teams in seat order, so that
+// team sizes differ by at most one. Only the host can balance teams.
+func (g *Game) BalanceTeams(hostID string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err := g.checkTeams(hostID); err != nil {
+		return err
+	}
+	for i, p := range g.seated() {
+		p.Team = i%g.Options.Teams + 1
+	}
+	return nil
+}
+
+func (g *Game) checkTeams(hostID string) error {
+	if err := g.checkHost(hostID); err != nil {
+		return err
+	}
+	if g.Options.Teams == 0 {
+		return ErrNoTeams
+	}
+	if g.State != Waiting {
+		return ErrGameStarted
+	}
+	return nil
+}
+
+// seated returns every player in seat order.
+func (g *Game) seated() []*Player {
+	players := make([]*Player, 0, len(g.Players))
+	for _, p := range g.Players {
+		players = append(players, p)
+	}
+	sort.Slice(players, func(i, j int) bool { return players[i].Seat < players[j].Seat })
+	return players
+}
+
+// smallestTeam returns the team with the fewest players, the lowest
+// numbered one on a tie.
+func (g *Game) smallestTeam() int {
+	sizes := make([]int, g.Options.Teams+1)
+	for _, p := range g.Players {
+		sizes[p.Team]++
+	}
+	team := 1
+	for t := 2; t <= g.Options.Teams; t++ {
+		if sizes[t] < sizes[team] {
+			team = t
+		}
+	}
+	return team
+}
+
+// checkTeamSizes reports whether every team has a player.
+func (g *Game) checkTeamSizes() error {
+	sizes := make([]int, g.Options.Teams+1)
+	for _, p := range g.Players {
+		sizes[p.Team]++
+	}
+	for t := 1; t <= g.Options.Teams; t++ {
+		if sizes[t] == 0 {
+			return ErrEmptyTeam
+		}
+	}
+	return nil
+}
+
+// teamMismos penalises teammates who picked the same number, once per
+// number, and returns the players left to play the round.
+func (g *Game) teamMismos(result *RoundResult, players []*Player) []*Player {
+	type key struct {
+		team   int
+		number string
+	}
+	groups := make(map[key][]*Player)
+	for _, p := range players {
+		k := key{p.Team, p.Number.String()}
+		groups[k] = append(groups[k], p)
+	}
+
+	var rest []*Player
+	for _, p := range players {
+		group := groups[key{p.Team, p.Number.String()}]
+		if len(group) < 2 {
+			rest = append(rest, p)
+			continue
+		}
+		result.Mismo = append(result.Mismo, p.ID)
+		if p != group[0] {
+			continue
+		}
+		result.TeamMismo = append(result.TeamMismo, p.Team)
+		if g.Options.Ties.Penalty == TieEliminate {
+			p.Lives = 0
+		} else {
+			p.Lives--
+		}
+	}
+	sort.Ints(result.TeamMismo)
+	return rest
+}
+
+// poolTeamLives charges every life the members of a team lost or gained
+// this round to the team's pool, given their lives before the round.
+func (g *Game) poolTeamLives(before map[string]int) {
+	pools := make(map[int]int)
+	for id, lives := range before {
+		p := g.Players[id]
+		if _, ok := pools[p.Team]; !ok {
+			pools[p.Team] = lives
+		}
+		pools[p.Team] -= lives - p.Lives
+	}
+	for id := range before {
+		p := g.Players[id]
+		lives := pools[p.Team]
+		if lives > g.Options.Lives {
+			lives = g.Options.Lives
+		}
+		if lives < 0 {
+			lives = 0
+		}
+		p.Lives = lives
+	}
+}
+
+// sidesLeft counts the teams, or in a game without teams the players, who
+// are still in the game.
+func (g *Game) sidesLeft() int {
+	sides := make(map[int]bool)
+	living := g.living()
+	if g.Options.Teams == 0 {
+		return len(living)
+	}
+	for _, p := range living {
+		sides[p.Team] = true
+	}
+	return len(sides)
+}
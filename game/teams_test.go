@@ -0,0 +1,62 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPoolTeamLives(t *testing.T) {
+	team := map[string]int{"a1": 1, "a2": 1, "b1": 2, "b2": 2}
+	tests := []struct {
+		name   string
+		before map[string]int // every member shares the team's pool
+		after  map[string]int // lives once the round's penalties are applied
+		want   map[string]int
+	}{
+		{
+			name:   "nobody lost a life",
+			before: map[string]int{"a1": 5, "a2": 5, "b1": 4, "b2": 4},
+			after:  map[string]int{"a1": 5, "a2": 5, "b1": 4, "b2": 4},
+			want:   map[string]int{"a1": 5, "a2": 5, "b1": 4, "b2": 4},
+		},
+		{
+			name:   "each member's losses come off the pool",
+			before: map[string]int{"a1": 5, "a2": 5, "b1": 4, "b2": 4},
+			after:  map[string]int{"a1": 4, "a2": 4, "b1": 4, "b2": 2},
+			want:   map[string]int{"a1": 3, "a2": 3, "b1": 2, "b2": 2},
+		},
+		{
+			name:   "a gain offsets a teammate's loss",
+			before: map[string]int{"a1": 3, "a2": 3, "b1": 4, "b2": 4},
+			after:  map[string]int{"a1": 4, "a2": 2, "b1": 4, "b2": 4},
+			want:   map[string]int{"a1": 3, "a2": 3, "b1": 4, "b2": 4},
+		},
+		{
+			name:   "losses beyond the pool stop at zero",
+			before: map[string]int{"a1": 2, "a2": 2, "b1": 4, "b2": 4},
+			after:  map[string]int{"a1": 0, "a2": 0, "b1": 3, "b2": 4},
+			want:   map[string]int{"a1": 0, "a2": 0, "b1": 3, "b2": 3},
+		},
+		{
+			name:   "gains stop at the starting lives",
+			before: map[string]int{"a1": 5, "a2": 5, "b1": 4, "b2": 4},
+			after:  map[string]int{"a1": 5, "a2": 5, "b1": 5, "b2": 5},
+			want:   map[string]int{"a1": 5, "a2": 5, "b1": 5, "b2": 5},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.Lives = 5
+			opts.Teams = 2
+			g := NewGame("test", opts)
+			for id, lives := range tt.after {
+				g.Players[id] = &Player{ID: id, Name: id, Lives: lives, Team: team[id]}
+			}
+			g.poolTeamLives(tt.before)
+			if got := lives(g); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lives = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// TiePenalty is what a mismo costs its players.
+type TiePenalty string
+
+const (
+	TieLoseLife  TiePenalty = "life"
+	TieEliminate TiePenalty = "eliminate"
+)
+
+// SpeedTieBreak picks a single loser among players sharing an extreme by
+// when they submitted.
+type SpeedTieBreak string
+
+const (
+	SpeedOff SpeedTieBreak = ""
+	// SpeedLast penalises the player who submitted last.
+	SpeedLast SpeedTieBreak = "last"
+	// SpeedFirst penalises the player who submitted first.
+	SpeedFirst SpeedTieBreak = "first"
+)
+
+// TieRules say how players who submit the same number are treated.
+type TieRules struct {
+	// A mismo is a group of at least MismoMin and, unless MismoMax is 0, at
+	// most MismoMax players on the same number. MismoMin 0 disables mismos.
+	MismoMin int        `json:"mismoMin"`
+	MismoMax int        `json:"mismoMax,omitempty"`
+	Penalty  TiePenalty `json:"penalty"`
+	// MismoOverridesExtreme leaves players in a mismo out when finding the
+	// minimum and maximum, so they are only penalised once.
+	MismoOverridesExtreme bool `json:"mismoOverridesExtreme"`
+	// SharedExtremes penalises every player on the minimum or maximum when
+	// several share it; otherwise a shared extreme costs nobody a life.
+	SharedExtremes bool `json:"sharedExtremes"`
+	// SpeedTieBreak, when set, overrides SharedExtremes: of the players
+	// sharing an extreme only one, chosen by submission time, is penalised.
+	// It cannot be combined with MismoOverridesExtreme, which would leave
+	// no shared extreme to break whenever a tie is a mismo.
+	SpeedTieBreak SpeedTieBreak `json:"speedTieBreak,omitempty"`
+}
+
+var (
+	// DefaultTieRules: any tie is a mismo costing a life, and the minimum
+	// and maximum are taken among the numbers nobody else picked.
+	DefaultTieRules = TieRules{MismoMin: 2, Penalty: TieLoseLife, MismoOverridesExtreme: true, SharedExtremes: true}
+	// ClassicTieRules: exactly two players on a number are eliminated,
+	// larger ties are harmless, and everyone on the minimum or maximum
+	// loses a life, mismo or not.
+	ClassicTieRules = TieRules{MismoMin: 2, MismoMax: 2, Penalty: TieEliminate, SharedExtremes: true}
+)
+
+func (r TieRules) validate() error {
+	switch {
+	case r.MismoMin == 1 || r.MismoMin < 0:
+		return fmt.Errorf("%w: a mismo needs at least 2 players", ErrInvalidOptions)
+	case r.MismoMax != 0 && r.MismoMax < r.MismoMin:
+		return fmt.Errorf("%w: mismo maximum is below the minimum", ErrInvalidOptions)
+	case r.Penalty != TieLoseLife && r.Penalty != TieEliminate:
+		return fmt.Errorf("%w: unknown tie penalty %q", ErrInvalidOptions, r.Penalty)
+	case r.SpeedTieBreak != SpeedOff && r.SpeedTieBreak != SpeedLast && r.SpeedTieBreak != SpeedFirst:
+		return fmt.Errorf("%w: unknown speed tie-break %q", ErrInvalidOptions, r.SpeedTieBreak)
+	case r.SpeedTieBreak != SpeedOff && r.MismoOverridesExtreme:
+		return fmt.Errorf("%w: a speed tie-break needs mismos to count as extremes", ErrInvalidOptions)
+	}
+	return nil
+}
+
+func (r TieRules) isMismo(players int) bool {
+	return r.MismoMin > 0 && players >= r.MismoMin && (r.MismoMax == 0 || players <= r.MismoMax)
+}
+
+// Pick is a number submitted by a player. At is when the server received
+// it, with a monotonic clock reading, and is only used by SpeedTieBreak.
+type Pick struct {
+	PlayerID string
+	Number   Number
+	At       time.Time
+}
+
+func picksOf(players []*Player) []Pick {
+	picks := make([]Pick, 0, len(players))
+	for _, p := range players {
+		picks = append(picks, Pick{PlayerID: p.ID, Number: *p.Number, At: p.SubmittedAt})
+	}
+	return picks
+}
+
+// Penalties are the outcome of a round's picks under the tie rules.
+type Penalties struct {
+	// Mismo lists the players in a mismo.
+	Mismo []string
+	// Min and Max are the extremes, unset when no pick was considered.
+	Min, Max *Number
+	// Extremes lists the players who lose a life for the minimum or the
+	// maximum; a player who is both appears twice.
+	Extremes []string
+	// LostOnTime lists the players who lost a shared extreme by
+	// SpeedTieBreak.
+	LostOnTime []string
+}
+
+// Apply works out who is penalised for a set of picks. Lists are sorted by
+// player ID.
+func (r TieRules) Apply(picks []Pick) Penalties {
+	var out Penalties
+	groups := make(map[string][]Pick) // by decimal string
+	for _, pick := range picks {
+		key := pick.Number.String()
+		groups[key] = append(groups[key], pick)
+	}
+
+	inMismo := make(map[string]bool)
+	for _, group := range groups {
+		if r.isMismo(len(group)) {
+			for _, pick := range group {
+				inMismo[pick.PlayerID] = true
+				out.Mismo = append(out.Mismo, pick.PlayerID)
+			}
+		}
+	}
+
+	var min, max *Pick
+	for i, pick := range picks {
+		if r.MismoOverridesExtreme && inMismo[pick.PlayerID] {
+			continue
+		}
+		if min == nil || pick.Number.Cmp(min.Number) < 0 {
+			min = &picks[i]
+		}
+		if max == nil || pick.Number.Cmp(max.Number) > 0 {
+			max = &picks[i]
+		}
+	}
+
+	for _, extreme := range []*Pick{min, max} {
+		if extreme == nil {
+			continue
+		}
+		var losers []Pick
+		for _, pick := range groups[extreme.Number.String()] {
+			if !(r.MismoOverridesExtreme && inMismo[pick.PlayerID]) {
+				losers = append(losers, pick)
+			}
+		}
+		switch {
+		case len(losers) > 1 && r.SpeedTieBreak != SpeedOff:
+			loser := r.bySpeed(losers)
+			out.Extremes = append(out.Extremes, loser.PlayerID)
+			out.LostOnTime = append(out.LostOnTime, loser.PlayerID)
+		case len(losers) == 1 || r.SharedExtremes:
+			for _, pick := range losers {
+				out.Extremes = append(out.Extremes, pick.PlayerID)
+			}
+		}
+	}
+	if min != nil {
+		minNumber, maxNumber := min.Number, max.Number
+		out.Min, out.Max = &minNumber, &maxNumber
+	}
+
+	sort.Strings(out.Mismo)
+	sort.Strings(out.Extremes)
+	sort.Strings(out.LostOnTime)
+	return out
+}
+
+// bySpeed returns the pick that loses a tie under SpeedTieBreak. Equal
+// times fall back to the player ID so the outcome is deterministic.
+func (r TieRules) bySpeed(picks []Pick) Pick {
+	loser := picks[0]
+	for _, pick := range picks[1:] {
+		later := pick.At.After(loser.At) || (pick.At.Equal(loser.At) && pick.PlayerID > loser.PlayerID)
+		if later == (r.SpeedTieBreak == SpeedLast) {
+			loser = pick
+		}
+	}
+	return loser
+}
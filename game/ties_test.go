@@ -0,0 +1,208 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// picks builds picks from alternating player IDs and numbers, submitted a
+// second apart in the order given.
+func picks(pairs ...interface{}) []Pick {
+	start := time.Unix(0, 0)
+	var out []Pick
+	for i := 0; i < len(pairs); i += 2 {
+		out = append(out, Pick{
+			PlayerID: pairs[i].(string),
+			Number:   NewNumber(uint64(pairs[i+1].(int))),
+			At:       start.Add(time.Duration(i/2) * time.Second),
+		})
+	}
+	return out
+}
+
+func number(n uint64) *Number {
+	v := NewNumber(n)
+	return &v
+}
+
+func formatPenalties(p Penalties) string {
+	return fmt.Sprintf("{Mismo: %v, Min: %v, Max: %v, Extremes: %v, LostOnTime: %v}", p.Mismo, p.Min, p.Max, p.Extremes, p.LostOnTime)
+}
+
+func TestTieRulesApply(t *testing.T) {
+	noMismo := TieRules{Penalty: TieLoseLife, SharedExtremes: true}
+
+	tests := []struct {
+		name  string
+		rules TieRules
+		picks []Pick
+		want  Penalties
+	}{
+		{
+			name:  "default: distinct numbers penalise the extremes",
+			rules: DefaultTieRules,
+			picks: picks("a", 1, "b", 5, "c", 9),
+			want:  Penalties{Min: number(1), Max: number(9), Extremes: []string{"a", "c"}},
+		},
+		{
+			name:  "default: a mismo is left out of the extremes",
+			rules: DefaultTieRules,
+			picks: picks("a", 1, "b", 1, "c", 5, "d", 9),
+			want:  Penalties{Mismo: []string{"a", "b"}, Min: number(5), Max: number(9), Extremes: []string{"c", "d"}},
+		},
+		{
+			name:  "default: every pick in a mismo leaves no extremes",
+			rules: DefaultTieRules,
+			picks: picks("a", 3, "b", 3, "c", 3),
+			want:  Penalties{Mismo: []string{"a", "b", "c"}},
+		},
+		{
+			name:  "default: a lone player is both minimum and maximum",
+			rules: DefaultTieRules,
+			picks: picks("a", 4),
+			want:  Penalties{Min: number(4), Max: number(4), Extremes: []string{"a", "a"}},
+		},
+		{
+			name:  "MismoMin 0 disables mismos",
+			rules: noMismo,
+			picks: picks("a", 1, "b", 1, "c", 9),
+			want:  Penalties{Min: number(1), Max: number(9), Extremes: []string{"a", "b", "c"}},
+		},
+		{
+			name:  "MismoMin 3 ignores pairs",
+			rules: TieRules{MismoMin: 3, Penalty: TieLoseLife, MismoOverridesExtreme: true, SharedExtremes: true},
+			picks: picks("a", 2, "b", 2, "c", 5, "d", 5, "e", 5, "f", 9),
+			want:  Penalties{Mismo: []string{"c", "d", "e"}, Min: number(2), Max: number(9), Extremes: []string{"a", "b", "f"}},
+		},
+		{
+			name:  "MismoMax 2 makes larger ties harmless",
+			rules: TieRules{MismoMin: 2, MismoMax: 2, Penalty: TieLoseLife, MismoOverridesExtreme: true, SharedExtremes: true},
+			picks: picks("a", 4, "b", 4, "c", 4, "d", 6, "e", 6, "f", 1, "g", 9),
+			want:  Penalties{Mismo: []string{"d", "e"}, Min: number(1), Max: number(9), Extremes: []string{"f", "g"}},
+		},
+		{
+			name:  "without MismoOverridesExtreme a mismo on an extreme is penalised twice",
+			rules: ClassicTieRules,
+			picks: picks("a", 1, "b", 1, "c", 5, "d", 9),
+			want:  Penalties{Mismo: []string{"a", "b"}, Min: number(1), Max: number(9), Extremes: []string{"a", "b", "d"}},
+		},
+		{
+			name:  "without SharedExtremes a shared extreme costs nobody",
+			rules: TieRules{Penalty: TieLoseLife},
+			picks: picks("a", 1, "b", 1, "c", 5, "d", 9),
+			want:  Penalties{Min: number(1), Max: number(9), Extremes: []string{"d"}},
+		},
+		{
+			name:  "SharedExtremes penalises everyone on the extreme",
+			rules: noMismo,
+			picks: picks("a", 1, "b", 5, "c", 9, "d", 9, "e", 9),
+			want:  Penalties{Min: number(1), Max: number(9), Extremes: []string{"a", "c", "d", "e"}},
+		},
+		{
+			name:  "SpeedLast penalises the last to submit",
+			rules: TieRules{Penalty: TieLoseLife, SpeedTieBreak: SpeedLast},
+			picks: picks("a", 1, "b", 1, "c", 5, "d", 9, "e", 9),
+			want:  Penalties{Min: number(1), Max: number(9), Extremes: []string{"b", "e"}, LostOnTime: []string{"b", "e"}},
+		},
+		{
+			name:  "SpeedFirst penalises the first to submit",
+			rules: TieRules{Penalty: TieLoseLife, SharedExtremes: true, SpeedTieBreak: SpeedFirst},
+			picks: picks("a", 1, "b", 1, "c", 5, "d", 9),
+			want:  Penalties{Min: number(1), Max: number(9), Extremes: []string{"a", "d"}, LostOnTime: []string{"a"}},
+		},
+		{
+			name:  "SpeedTieBreak applies to a mismo that is not left out",
+			rules: TieRules{MismoMin: 2, MismoMax: 2, Penalty: TieEliminate, SharedExtremes: true, SpeedTieBreak: SpeedLast},
+			picks: picks("a", 1, "b", 1, "c", 5, "d", 9),
+			want:  Penalties{Mismo: []string{"a", "b"}, Min: number(1), Max: number(9), Extremes: []string{"b", "d"}, LostOnTime: []string{"b"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.rules.validate(); err != nil {
+				t.Fatalf("rules are invalid: %v", err)
+			}
+			got := tt.rules.Apply(tt.picks)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Apply() = %s, want %s", formatPenalties(got), formatPenalties(tt.want))
+			}
+		})
+	}
+}
+
+func TestSpeedTieBreakEqualTimes(t *testing.T) {
+	at := time.Unix(0, 0)
+	tied := []Pick{
+		{PlayerID: "b", Number: NewNumber(1), At: at},
+		{PlayerID: "a", Number: NewNumber(1), At: at},
+		{PlayerID: "c", Number: NewNumber(2), At: at},
+	}
+	for speed, want := range map[SpeedTieBreak]string{SpeedLast: "b", SpeedFirst: "a"} {
+		rules := TieRules{Penalty: TieLoseLife, SpeedTieBreak: speed}
+		if got := rules.Apply(tied).LostOnTime; !reflect.DeepEqual(got, []string{want}) {
+			t.Errorf("%s: LostOnTime = %v, want [%s]", speed, got, want)
+		}
+	}
+}
+
+func TestTieRulesValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules TieRules
+		ok    bool
+	}{
+		{"default", DefaultTieRules, true},
+		{"classic", ClassicTieRules, true},
+		{"no mismos", TieRules{Penalty: TieLoseLife}, true},
+		{"mismo of one", TieRules{MismoMin: 1, Penalty: TieLoseLife}, false},
+		{"negative mismo", TieRules{MismoMin: -2, Penalty: TieLoseLife}, false},
+		{"maximum below minimum", TieRules{MismoMin: 3, MismoMax: 2, Penalty: TieLoseLife}, false},
+		{"unknown penalty", TieRules{MismoMin: 2, Penalty: "jail"}, false},
+		{"unknown speed tie-break", TieRules{Penalty: TieLoseLife, SpeedTieBreak: "middle"}, false},
+		{"speed tie-break with classic rules", TieRules{MismoMin: 2, MismoMax: 2, Penalty: TieEliminate, SpeedTieBreak: SpeedLast}, true},
+		{"speed tie-break with mismos overriding extremes", TieRules{MismoMin: 2, Penalty: TieLoseLife, MismoOverridesExtreme: true, SpeedTieBreak: SpeedLast}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rules.validate()
+			if ok := err == nil; ok != tt.ok {
+				t.Fatalf("validate() = %v, want ok %v", err, tt.ok)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidOptions) {
+				t.Errorf("validate() = %v, want ErrInvalidOptions", err)
+			}
+		})
+	}
+}
+
+func TestTiePenalty(t *testing.T) {
+	tests := []struct {
+		penalty TiePenalty
+		want    int // lives left to the players in the mismo
+	}{
+		{TieLoseLife, 2},
+		{TieEliminate, 0},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.penalty), func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.Lives = 3
+			opts.Ties.Penalty = tt.penalty
+			g := playRound(t, opts, map[string]uint64{"a": 5, "b": 5, "c": 1, "d": 9})
+
+			for _, id := range []string{"a", "b"} {
+				if lives := g.Players[id].Lives; lives != tt.want {
+					t.Errorf("%s has %d lives, want %d", id, lives, tt.want)
+				}
+			}
+			for _, id := range []string{"c", "d"} {
+				if lives := g.Players[id].Lives; lives != 2 {
+					t.Errorf("%s has %d lives, want 2", id, lives)
+				}
+			}
+		})
+	}
+}
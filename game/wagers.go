@@ -0,0 +1,111 @@
+package game
+
+import (
+	"errors"
+	"sort"
+)
+
+var (
+	ErrNoWagers       = errors.New("game has no wagers")
+	ErrAlreadyWagered = errors.New("player has already wagered this round")
+	ErrCannotWager    = errors.New("a wager needs a life to spare and one to win")
+)
+
+// Wager is the outcome of a life a player wagered on being safe: not
+// losing a life to the round's rule nor being in a mismo. A safe player
+// wins the life back doubled; otherwise it is lost.
+type Wager struct {
+	PlayerID string `json:"playerId"`
+	Won      bool   `json:"won"`
+}
+
+// PlaceWager wagers one of a player's lives on the current round, before
+// their number is locked. The player must have more than one life left
+// and fewer than the starting lives, so that the wager can be won.
+func (g *Game) PlaceWager(playerID string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	player, exists := g.Players[playerID]
+	if !exists {
+		return ErrPlayerNotFound
+	}
+	if !g.Options.Wagers {
+		return ErrNoWagers
+	}
+	if player.Lives <= 0 {
+		return ErrEliminated
+	}
+	if g.State != Playing {
+		return ErrNotPlaying
+	}
+	if player.Locked {
+		return ErrNumberLocked
+	}
+	if player.Wagered {
+		return ErrAlreadyWagered
+	}
+	if player.Lives < 2 || player.Lives >= g.Options.Lives {
+		return ErrCannotWager
+	}
+	player.Wagered = true
+	return nil
+}
+
+// settleWagers resolves the wagers placed this round.
+func (g *Game) settleWagers(result *RoundResult) {
+	penalised := make(map[string]bool)
+	for _, id := range result.LostLife {
+		penalised[id] = true
+	}
+	for _, id := range result.Mismo {
+		penalised[id] = true
+	}
+
+	for _, p := range g.seated() {
+		if !p.Wagered {
+			continue
+		}
+		_, played := result.Numbers[p.ID]
+		won := played && !penalised[p.ID]
+		if won {
+			p.Lives = min(p.Lives+1, g.Options.Lives)
+		} else {
+			p.Lives = max(p.Lives-1, 0)
+		}
+		result.Wagers = append(result.Wagers, Wager{PlayerID: p.ID, Won: won})
+	}
+}
+
+// awardMedianBonus gives a life, up to the starting lives, to the single
+// player who picked the exact median of the round's numbers.
+func (g *Game) awardMedianBonus(result *RoundResult) {
+	numbers := make([]Number, 0, len(result.Numbers))
+	for _, n := range result.Numbers {
+		numbers = append(numbers, n)
+	}
+	if len(numbers) == 0 {
+		return
+	}
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i].Cmp(numbers[j]) < 0 })
+	mid := numbers[len(numbers)/2]
+	if len(numbers)%2 == 0 && numbers[len(numbers)/2-1].Cmp(mid) != 0 {
+		return // the median falls between two numbers
+	}
+	result.Median = &mid
+
+	var pickers []string
+	for id, n := range result.Numbers {
+		if n.Cmp(mid) == 0 {
+			pickers = append(pickers, id)
+		}
+	}
+	if len(pickers) != 1 {
+		return
+	}
+	p := g.Players[pickers[0]]
+	if p.Lives > 0 && p.Lives < g.Options.Lives {
+		p.Lives++
+		result.GainedLife = append(result.GainedLife, p.ID)
+	}
+}
@@ -0,0 +1,121 @@
+package game
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPlaceWagerBoundaries(t *testing.T) {
+	tests := []struct {
+		lives int
+		err   error
+	}{
+		{1, ErrCannotWager}, // nothing to spare
+		{2, nil},
+		{4, nil},
+		{5, ErrCannotWager}, // nothing to win at the starting lives
+	}
+	for _, tt := range tests {
+		opts := DefaultOptions()
+		opts.Lives = 5
+		opts.Wagers = true
+		g := startGame(t, opts, "a", "b", "c")
+		g.Players["a"].Lives = tt.lives
+		if err := g.PlaceWager("a"); !errors.Is(err, tt.err) {
+			t.Errorf("wager at %d lives: err = %v, want %v", tt.lives, err, tt.err)
+		}
+	}
+}
+
+func TestSettleWagers(t *testing.T) {
+	tests := []struct {
+		name       string
+		lives      int
+		doubleDown bool
+		numbers    map[string]uint64
+		want       int
+		won        bool
+	}{
+		{
+			name:    "safe at two lives",
+			lives:   2,
+			numbers: map[string]uint64{"a": 5, "b": 1, "c": 9},
+			want:    3,
+			won:     true,
+		},
+		{
+			name:    "lost at one life left after the round",
+			lives:   2,
+			numbers: map[string]uint64{"a": 1, "b": 5, "c": 9},
+			want:    0,
+		},
+		{
+			name:    "safe just below the starting lives is capped",
+			lives:   4,
+			numbers: map[string]uint64{"a": 5, "b": 1, "c": 9},
+			want:    5,
+			won:     true,
+		},
+		{
+			name:       "double-down and wager both lost",
+			lives:      3,
+			doubleDown: true,
+			numbers:    map[string]uint64{"a": 1, "b": 5, "c": 9},
+			want:       0,
+		},
+		{
+			name:       "double-down and wager lost with lives to spare",
+			lives:      4,
+			doubleDown: true,
+			numbers:    map[string]uint64{"a": 1, "b": 5, "c": 9},
+			want:       1,
+		},
+		{
+			name:       "double-down and wager both won",
+			lives:      3,
+			doubleDown: true,
+			numbers:    map[string]uint64{"a": 5, "b": 1, "c": 9},
+			want:       5,
+			won:        true,
+		},
+		{
+			name:       "double-down and wager won are capped",
+			lives:      4,
+			doubleDown: true,
+			numbers:    map[string]uint64{"a": 5, "b": 1, "c": 9},
+			want:       5,
+			won:        true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.Lives = 5
+			opts.Wagers = true
+			opts.CardEvery = 1
+			g := startGame(t, opts, "a", "b", "c")
+			g.Players["a"].Lives = tt.lives
+			if err := g.PlaceWager("a"); err != nil {
+				t.Fatalf("PlaceWager: %v", err)
+			}
+			if tt.doubleDown {
+				g.Players["a"].Cards = []Card{CardDoubleDown}
+				if err := g.PlayCard("a", CardDoubleDown, ""); err != nil {
+					t.Fatalf("PlayCard: %v", err)
+				}
+			}
+			submitAll(t, g, tt.numbers)
+
+			if got := g.Players["a"].Lives; got != tt.want {
+				t.Errorf("lives = %d, want %d", got, tt.want)
+			}
+			want := Wager{PlayerID: "a", Won: tt.won}
+			if got := g.LastRound.Wagers; len(got) != 1 || got[0] != want {
+				t.Errorf("wagers = %+v, want [%+v]", got, want)
+			}
+			if tt.want == 0 && g.Players["a"].EliminatedRound != 1 {
+				t.Errorf("eliminated in round %d, want 1", g.Players["a"].EliminatedRound)
+			}
+		})
+	}
+}
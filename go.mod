@@ -3,6 +3,10 @@ module mismo
 go 1.22.6
 
 require (
-	github.com/google/uuid v1.6.0 // indirect
-	github.com/gorilla/websocket v1.5.3 // indirect
+	github.com/google/uuid v1.6.0
+	github.com/gorilla/websocket v1.5.3
+	golang.org/x/crypto v0.31.0
+	golang.org/x/text v0.21.0
 )
+
+require golang.org/x/sys v0.28.0 // indirect
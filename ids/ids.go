@@ -0,0 +1,70 @@
+// Package ids generates identifiers from crypto randomness: short game codes
+// meant to be read out and typed by people, and long secrets meant to be
+// used as credentials.
+package ids
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"strings"
+)
+
+const (
+	// CodeAlphabet leaves out characters that are easily confused when
+	// read aloud or handwritten (0/O, 1/I/L).
+	CodeAlphabet = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+	CodeLength   = 6
+
+	// secretBytes gives secrets 128 bits of entropy.
+	secretBytes = 16
+	// maxAttempts bounds collision retries; with 31^6 codes a retry is
+	// already unlikely, so running out means the space is nearly full.
+	maxAttempts = 16
+)
+
+var ErrExhausted = errors.New("could not generate an unused id")
+
+// GameCode returns a random human-friendly game code.
+func GameCode() (string, error) {
+	alphabetSize := big.NewInt(int64(len(CodeAlphabet)))
+	var b strings.Builder
+	for i := 0; i < CodeLength; i++ {
+		n, err := rand.Int(rand.Reader, alphabetSize)
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(CodeAlphabet[n.Int64()])
+	}
+	return b.String(), nil
+}
+
+// NormalizeCode turns user input into the canonical form of a game code.
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Secret returns a random URL-safe secret suitable as a credential.
+func Secret() (string, error) {
+	b := make([]byte, secretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Unique calls generate until it returns an id for which taken is false.
+// Callers must hold whatever lock protects the set that taken consults.
+func Unique(generate func() (string, error), taken func(string) bool) (string, error) {
+	for i := 0; i < maxAttempts; i++ {
+		id, err := generate()
+		if err != nil {
+			return "", err
+		}
+		if !taken(id) {
+			return id, nil
+		}
+	}
+	return "", ErrExhausted
+}
@@ -0,0 +1,310 @@
+// lobby.go
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+
+	"mismo/game"
+	"mismo/ratelimit"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	defaultTableSize = 4
+	minTableSize     = 3
+	maxTableSize     = 8
+)
+
+// Lobby is the public summary of a game shown in the lobby browser.
+type Lobby struct {
+	ID      string         `json:"id"`
+	State   game.GameState `json:"state"`
+	Round   int            `json:"round"`
+	Players []string       `json:"players"`
+	Options Options        `json:"options"`
+}
+
+// lobbyHub keeps the connections subscribed to lobby updates.
+type lobbyHub struct {
+	conns map[*websocket.Conn]bool
+	mu    sync.Mutex
+}
+
+var lobbies = &lobbyHub{conns: make(map[*websocket.Conn]bool)}
+
+// listLobbies returns the public games that have not finished yet.
+func listLobbies() []Lobby {
+	gamesMu.Lock()
+	list := make([]*Game, 0, len(games))
+	for _, g := range games {
+		list = append(list, g)
+	}
+	gamesMu.Unlock()
+
+	result := make([]Lobby, 0)
+	for _, g := range list {
+		if !g.Options.Public {
+			continue
+		}
+		snapshot := g.engine.Snapshot()
+		if snapshot.State == game.Finished {
+			continue
+		}
+		lobby := Lobby{
+			ID:      g.ID,
+			State:   snapshot.State,
+			Round:   snapshot.Round,
+			Players: make([]string, 0, len(snapshot.Players)),
+			Options: g.Options,
+		}
+		for _, p := range snapshot.Players {
+			lobby.Players = append(lobby.Players, p.Name)
+		}
+		sort.Strings(lobby.Players)
+		result = append(result, lobby)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+// notify sends the current lobby list to every subscriber.
+func (h *lobbyHub) notify() {
+	list := listLobbies()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for conn := range h.conns {
+		if err := conn.WriteJSON(lobbiesMessage{Type: "lobbies", Lobbies: list}); err != nil {
+			log.Printf("Error sending lobbies: %v", err)
+			conn.Close()
+			delete(h.conns, conn)
+		}
+	}
+}
+
+// lobbiesResponse is the body returned by lobbiesHandler.
+type lobbiesResponse struct {
+	Lobbies []Lobby `json:"lobbies"`
+}
+
+// lobbiesMessage is sent to lobby subscribers whenever the list changes.
+type lobbiesMessage struct {
+	Type    string  `json:"type"`
+	Lobbies []Lobby `json:"lobbies"`
+}
+
+// lobbyMessages are the messages sent over /ws/lobbies.
+var lobbyMessages = []wsMessage{
+	{Type: "lobbies", Summary: "The public games, sent on connecting and after every change.", Payload: lobbiesMessage{}},
+}
+
+// lobbiesHandler lists the public games.
+func lobbiesHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(lobbiesResponse{Lobbies: listLobbies()})
+}
+
+// lobbiesWsHandler streams the lobby list every time a public game changes.
+func lobbiesWsHandler(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("WebSocket Upgrade Error: %v", err)
+		return
+	}
+
+	lobbies.mu.Lock()
+	err = conn.WriteJSON(lobbiesMessage{Type: "lobbies", Lobbies: listLobbies()})
+	if err == nil {
+		lobbies.conns[conn] = true
+	}
+	lobbies.mu.Unlock()
+	if err != nil {
+		conn.Close()
+		return
+	}
+	conn.SetReadLimit(maxMessageSize)
+
+	// Subscribers only listen; reading detects when they go away.
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			break
+		}
+	}
+	lobbies.mu.Lock()
+	delete(lobbies.conns, conn)
+	lobbies.mu.Unlock()
+	conn.Close()
+}
+
+// queueMessage asks to be matched into a table of Size players.
+type queueMessage struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+	Size int    `json:"size,omitempty"`
+	// Account is a token from POST /api/v1/accounts; tables are balanced by
+	// its rating. Join the matched game with it too.
+	Account string `json:"account,omitempty"`
+}
+
+type queuedMessage struct {
+	Type string `json:"type"`
+	Size int    `json:"size"`
+}
+
+// matchedMessage tells a queued player which game to join, under which name,
+// and how evenly matched the table is by rating.
+type matchedMessage struct {
+	Type    string  `json:"type"`
+	GameID  string  `json:"gameId"`
+	Name    string  `json:"name"`
+	Quality float64 `json:"quality"`
+}
+
+// matchmakingMessages are the messages players send over /ws/matchmaking,
+// and matchedMessages those they receive.
+var (
+	matchmakingMessages = []wsMessage{
+		{Type: "queue", Summary: "Wait for a table of the given size (3 to 8, default 4).", Payload: queueMessage{}},
+	}
+	matchedMessages = []wsMessage{
+		{Type: "queued", Summary: "Confirms the player is waiting.", Payload: queuedMessage{}},
+		{Type: "matched", Summary: "A table is ready; the connection closes after this.", Payload: matchedMessage{}},
+		{Summary: "A rejected message.", Payload: errorMessage{}},
+	}
+)
+
+// queuedPlayer is a player waiting in the matchmaking queue.
+type queuedPlayer struct {
+	name    string
+	account string // "" for unrated players
+	conn    *websocket.Conn
+}
+
+// matchmaker groups queued players into tables of their target size.
+type matchmaker struct {
+	queues map[int][]*queuedPlayer
+	mu     sync.Mutex
+}
+
+var matchmaking = &matchmaker{queues: make(map[int][]*queuedPlayer)}
+
+// enqueue adds a player to the queue for the given table size and, once
+// enough players are waiting, creates a game and tells them to join it.
+func (m *matchmaker) enqueue(qp *queuedPlayer, size int) {
+	m.mu.Lock()
+	m.queues[size] = append(m.queues[size], qp)
+	if len(m.queues[size]) < size {
+		m.mu.Unlock()
+		return
+	}
+	table := m.queues[size][:size]
+	m.queues[size] = append([]*queuedPlayer(nil), m.queues[size][size:]...)
+	m.mu.Unlock()
+
+	opts := defaultOptions()
+	opts.MinPlayers = size
+	g, err := newGame(opts, "")
+	if err != nil {
+		log.Printf("Error registering matched game: %v", err)
+		for _, p := range table {
+			p.conn.WriteJSON(errorMessage{Error: err.Error()})
+			p.conn.Close()
+		}
+		return
+	}
+
+	accounts := make([]string, len(table))
+	for i, p := range table {
+		accounts[i] = p.account
+	}
+	quality := ratings.Quality(accounts).Score
+	for _, p := range table {
+		if err := p.conn.WriteJSON(matchedMessage{Type: "matched", GameID: g.ID, Name: p.name, Quality: quality}); err != nil {
+			log.Printf("Error notifying matched player %s: %v", p.name, err)
+		}
+		p.conn.Close()
+	}
+}
+
+// remove takes a player out of every queue.
+func (m *matchmaker) remove(qp *queuedPlayer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for size, queue := range m.queues {
+		for i, p := range queue {
+			if p == qp {
+				m.queues[size] = append(queue[:i:i], queue[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// matchmakingHandler queues a player until a table of their target size is
+// ready. The client sends {"type": "queue", "name": ..., "size": ...} and
+// receives {"type": "matched", "gameId": ...} before the connection closes.
+func matchmakingHandler(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("WebSocket Upgrade Error: %v", err)
+		return
+	}
+	defer conn.Close()
+	conn.SetReadLimit(maxMessageSize)
+
+	var queued *queuedPlayer
+	for {
+		var msg queueMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			if _, ok := err.(*json.UnmarshalTypeError); ok && queued == nil {
+				conn.WriteJSON(errorMessage{Error: "Invalid message."})
+				continue
+			}
+			if queued != nil {
+				matchmaking.remove(queued)
+			}
+			break
+		}
+
+		if queued != nil {
+			// Already waiting; the matched notice may be written at any time.
+			continue
+		}
+		if msg.Type != "queue" {
+			conn.WriteJSON(errorMessage{Error: "Unknown message type."})
+			continue
+		}
+		if !joinLimiter.Allow(ratelimit.ClientIP(r)) {
+			conn.WriteJSON(errorMessage{Error: "Too many join attempts, try again later."})
+			continue
+		}
+		name, err := nameRules.Validate(msg.Name)
+		if err != nil {
+			conn.WriteJSON(errorMessage{Error: "Invalid name: " + err.Error() + "."})
+			continue
+		}
+		size := msg.Size
+		if size == 0 {
+			size = defaultTableSize
+		}
+		if size < minTableSize || size > maxTableSize {
+			conn.WriteJSON(errorMessage{Error: "Invalid table size."})
+			continue
+		}
+
+		account, err := verifyAccount(msg.Account)
+		if err != nil {
+			conn.WriteJSON(errorMessage{Error: err.Error()})
+			continue
+		}
+
+		queued = &queuedPlayer{name: name, account: account, conn: conn}
+		conn.WriteJSON(queuedMessage{Type: "queued", Size: size})
+		matchmaking.enqueue(queued, size)
+	}
+}
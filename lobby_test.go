@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// readLobbies reads the next lobby list and reports whether it shows a game.
+func readLobbies(t *testing.T, conn *websocket.Conn, gameID string) bool {
+	t.Helper()
+	var msg lobbiesMessage
+	if err := json.Unmarshal(readMessage(t, conn, "lobbies"), &msg); err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range msg.Lobbies {
+		if l.ID == gameID {
+			return true
+		}
+	}
+	return false
+}
+
+func TestLobbiesHearOfRemovedGames(t *testing.T) {
+	tests := []struct {
+		name   string
+		remove func(t *testing.T, g *Game)
+	}{
+		{"unregistered", func(t *testing.T, g *Game) { unregisterGame(g.ID) }},
+		{"pruned", func(t *testing.T, g *Game) {
+			g.mu.Lock()
+			g.active = time.Now().Add(-2 * idleGameTTL)
+			g.mu.Unlock()
+			// Fill the server so that the next game prunes the stale one.
+			for {
+				gamesMu.Lock()
+				full := len(games) >= maxGames
+				gamesMu.Unlock()
+				if full {
+					break
+				}
+				testGame(t, defaultOptions())
+			}
+			testGame(t, defaultOptions())
+			if _, ok := findGame(g.ID); ok {
+				t.Fatal("stale game was not pruned")
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := testServer(t)
+			opts := defaultOptions()
+			opts.Public = true
+			g := testGame(t, opts)
+
+			url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws/lobbies"
+			conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			if !readLobbies(t, conn, g.ID) {
+				t.Fatal("public game is not listed")
+			}
+
+			tt.remove(t, g)
+			if readLobbies(t, conn, g.ID) {
+				t.Error("removed game is still listed")
+			}
+		})
+	}
+}
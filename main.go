@@ -3,373 +3,630 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"log"
 	"net/http"
+	"os"
+	"strings"
 	"sync"
+	"time"
+
+	"mismo/auth"
+	"mismo/game"
+	"mismo/ids"
+	"mismo/ratelimit"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 )
 
-// Player represents a game participant.
-type Player struct {
-	ID        string          `json:"id"`
-	Name      string          `json:"name"`
-	Lives     int             `json:"lives"`
-	Number    int             `json:"number"`
-	HasPlayed bool            `json:"hasPlayed"`
-	IsHost    bool            `json:"isHost"`
-	Conn      *websocket.Conn `json:"-"`
-	mu        sync.Mutex      `json:"-"`
+// Options are the settings chosen when a game is created: the engine's
+// rules plus how the game can be found and joined.
+type Options struct {
+	game.Options
+	Public  bool `json:"public"`
+	Private bool `json:"private"`
+}
+
+// defaultOptions returns the settings used when none are given.
+func defaultOptions() Options {
+	opts := Options{Options: game.DefaultOptions()}
+	opts.MaxPlayers = maxPlayersPerGame
+	// The server has always played classic ties: a pair on a number is
+	// eliminated, larger ties are harmless.
+	opts.Ties = game.ClassicTieRules
+	return opts
 }
 
-// Game represents an instance of the game.
+// Game is a game hosted by this server: the engine running the rules, plus
+// the connections and access settings around it.
 type Game struct {
-	ID      string             `json:"id"`
-	Players map[string]*Player `json:"players"`
-	State   string             `json:"state"` // "waiting", "playing", "roundEnd", "gameOver"
-	Round   int                `json:"round"`
-	mu      sync.Mutex         `json:"-"`
+	ID       string
+	Options  Options
+	engine   *game.Game
+	clients  map[string]*client     // by player ID
+	rated    bool                   // whether the finished game has been rated
+	accounts map[string]string      // rated accounts by player ID
+	active   time.Time              // when the game last changed
+	timed    int                    // the last round a timer was set for
+	version  int                    // counts changes, as event stream IDs
+	streams  map[chan struct{}]bool // event streams to wake on a change
+	mu       sync.Mutex             // guards the fields above
+
+	// passwordHash guards joining a private game; invites work without it.
+	passwordHash string
+}
+
+// client is a WebSocket connection. Writes are serialised since broadcasts
+// and replies come from different goroutines.
+type client struct {
+	conn *websocket.Conn
+	mu   sync.Mutex
 }
 
+func (c *client) send(v interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conn.WriteJSON(v)
+}
+
+func (c *client) sendError(message string) {
+	c.send(errorMessage{Error: message})
+}
+
+// playerView is a player as sent to clients.
+type playerView struct {
+	ID              string       `json:"id"`
+	Name            string       `json:"name"`
+	Lives           int          `json:"lives"`
+	Number          *game.Number `json:"number"`
+	HasPlayed       bool         `json:"hasPlayed"`
+	Locked          bool         `json:"locked"`
+	Wagered         bool         `json:"wagered,omitempty"`
+	IsHost          bool         `json:"isHost"`
+	Team            int          `json:"team,omitempty"`
+	EliminatedRound int          `json:"eliminatedRound,omitempty"`
+	// CardCount is the size of the player's hand. Cards and Play, the card
+	// played this round, are only sent to the player themselves.
+	CardCount int            `json:"cardCount,omitempty"`
+	Cards     []game.Card    `json:"cards,omitempty"`
+	Play      *game.CardPlay `json:"play,omitempty"`
+}
+
+// stateMessage is the game state sent to clients, both as the WebSocket
+// broadcast and as the REST representation of a game.
+type stateMessage struct {
+	Type      string                `json:"type"`
+	ID        string                `json:"id"`
+	State     game.GameState        `json:"state"`
+	Phase     game.Phase            `json:"phase"`
+	Round     int                   `json:"round"`
+	Options   Options               `json:"options"`
+	Range     game.Range            `json:"range"`
+	Players   map[string]playerView `json:"players"`
+	LastRound *game.RoundResult     `json:"lastRound,omitempty"`
+	Deadline  *time.Time            `json:"deadline,omitempty"`
+	Standings *game.Standings       `json:"standings,omitempty"`
+}
+
+const (
+	// inviteTTL is how long an invite link stays valid.
+	inviteTTL = 24 * time.Hour
+	// tokenTTL is how long a player token stays valid.
+	tokenTTL = 24 * time.Hour
+	// accountTTL is how long an account token stays valid.
+	accountTTL = 365 * 24 * time.Hour
+
+	// Abuse limits.
+	maxMessageSize    = 4 << 10 // bytes per WebSocket message, enough for any valid chat message
+	maxBodySize       = 4 << 10 // bytes per HTTP request body
+	maxPlayersPerGame = 12
+	maxGames          = 1000
+	emptyGameTTL      = 10 * time.Minute // how long a game may wait for its first player
+	idleGameTTL       = time.Hour        // how long a game may go without any change
+	finishedGameTTL   = 10 * time.Minute // how long a finished game stays readable
+	messageRate       = 10               // WebSocket messages per second per connection
+	messageBurst      = 20
+)
+
 var (
-	games    = make(map[string]*Game)
-	gamesMu  sync.Mutex
+	errServerFull     = errors.New("Too many games on this server, try again later.")
+	errPublicPrivate  = errors.New("A game cannot be both public and private.")
+	errInvalidAccount = errors.New("Invalid account token.")
+	errAccountInGame  = errors.New("This account already has a seat in this game.")
+)
+
+var (
+	signer  *auth.Signer
+	games   = make(map[string]*Game)
+	gamesMu sync.Mutex
+	// origins lists the foreign origins allowed besides our own; see
+	// MISMO_ALLOWED_ORIGINS.
+	origins  = auth.OriginsFromEnv()
 	upgrader = websocket.Upgrader{
-		CheckOrigin: func(r *http.Request) bool { return true },
+		CheckOrigin: func(r *http.Request) bool { return origins.Check(r) },
 	}
-)
 
-// createGame initializes a new game with a unique ID.
-func createGame() *Game {
-	return &Game{
-		ID:      uuid.New().String()[:6],
-		Players: make(map[string]*Player),
-		State:   "waiting",
-		Round:   1,
+	// nameRules validate player names; MISMO_BLOCKED_WORDS adds a
+	// comma-separated word filter.
+	nameRules = game.NameRules{
+		MaxLength: game.DefaultNameRules.MaxLength,
+		Blocked:   strings.Split(os.Getenv("MISMO_BLOCKED_WORDS"), ","),
 	}
-}
 
-// broadcast sends the current game state to all connected players.
-func (g *Game) broadcast() {
-	g.mu.Lock()
-	state := map[string]interface{}{
-		"id":      g.ID,
-		"state":   g.State,
-		"round":   g.Round,
-		"players": g.Players,
+	// Per-IP limits on creating and joining games, and per-player limits on
+	// REST actions.
+	createLimiter = ratelimit.NewLimiter(5.0/60, 5)
+	joinLimiter   = ratelimit.NewLimiter(10.0/60, 10)
+	actionLimiter = ratelimit.NewLimiter(5, 10)
+)
+
+// newGame creates a game with an unused game code and makes it reachable.
+func newGame(opts Options, passwordHash string) (*Game, error) {
+	var pruned bool
+	defer func() {
+		// Listing the lobbies takes gamesMu, so they hear of pruned games
+		// once it is released.
+		if pruned {
+			lobbies.notify()
+		}
+	}()
+	gamesMu.Lock()
+	defer gamesMu.Unlock()
+
+	if len(games) >= maxGames {
+		pruned = pruneGames()
 	}
-	players := make([]*Player, 0, len(g.Players))
-	for _, p := range g.Players {
-		players = append(players, p)
+	if len(games) >= maxGames {
+		return nil, errServerFull
+	}
+	id, err := ids.Unique(ids.GameCode, func(id string) bool {
+		_, taken := games[id]
+		return taken
+	})
+	if err != nil {
+		return nil, err
 	}
-	g.mu.Unlock()
 
-	for _, p := range players {
-		p.mu.Lock()
-		if p.Conn != nil {
-			err := p.Conn.WriteJSON(state)
-			if err != nil {
-				log.Printf("Error broadcasting to player %s: %v", p.ID, err)
-				p.Conn.Close()
-				p.Conn = nil
-			}
-		}
-		p.mu.Unlock()
+	engine := game.NewGame(id, opts.Options)
+	engine.NameRules = nameRules
+	g := &Game{
+		ID:           id,
+		Options:      opts,
+		engine:       engine,
+		clients:      make(map[string]*client),
+		accounts:     make(map[string]string),
+		streams:      make(map[chan struct{}]bool),
+		passwordHash: passwordHash,
+		active:       time.Now(),
 	}
+	games[id] = g
+	return g, nil
 }
 
-// addPlayer adds a new player to the game.
-func (g *Game) addPlayer(name string, conn *websocket.Conn) *Player {
-	g.mu.Lock()
-	defer g.mu.Unlock()
-
-	isHost := len(g.Players) == 0
-	player := &Player{
-		ID:     uuid.New().String(),
-		Name:   name,
-		Lives:  7,
-		Conn:   conn,
-		IsHost: isHost,
-	}
-	g.Players[player.ID] = player
-	return player
+// findGame looks up a game by its code.
+func findGame(id string) (*Game, bool) {
+	gamesMu.Lock()
+	defer gamesMu.Unlock()
+	g, ok := games[ids.NormalizeCode(id)]
+	return g, ok
 }
 
-// removePlayer removes a player from the game.
-func (g *Game) removePlayer(playerID string) {
-	g.mu.Lock()
-	defer g.mu.Unlock()
-	delete(g.Players, playerID)
+// pruneGames drops games nobody joined in time, games finished a while ago
+// and games nobody has acted in for idleGameTTL, and reports whether a
+// public one was among them. Callers must hold gamesMu.
+func pruneGames() bool {
+	public := false
+	for id, g := range games {
+		snapshot := g.engine.Snapshot()
+		g.mu.Lock()
+		idle := time.Since(g.active)
+		g.mu.Unlock()
+
+		switch {
+		case len(snapshot.Players) == 0 && idle > emptyGameTTL,
+			snapshot.State == game.Finished && idle > finishedGameTTL,
+			idle > idleGameTTL:
+			delete(games, id)
+			public = public || g.Options.Public
+		}
+	}
+	return public
 }
 
-// submitNumber processes a player's number submission.
-func (g *Game) submitNumber(playerID string, number int) {
-	g.mu.Lock()
-	defer g.mu.Unlock()
+// unregisterGame forgets a game, freeing its slot and code.
+func unregisterGame(id string) {
+	gamesMu.Lock()
+	g, ok := games[id]
+	delete(games, id)
+	gamesMu.Unlock()
 
-	if g.State != "playing" {
-		return
+	if ok && g.Options.Public {
+		lobbies.notify()
 	}
+}
 
-	if player, exists := g.Players[playerID]; exists && !player.HasPlayed && player.Lives > 0 {
-		player.Number = number
-		player.HasPlayed = true
+// state projects the engine state for a player, or for anyone when viewer
+// is empty.
+func (g *Game) state(viewer string) stateMessage {
+	return g.project(g.engine.Snapshot(), viewer)
+}
 
-		// Check if all active players have submitted their numbers.
-		allPlayed := true
-		for _, p := range g.Players {
-			if p.Lives > 0 && !p.HasPlayed {
-				allPlayed = false
-				break
+// project turns a snapshot into the state a viewer may see: players' hands
+// are private, other players' numbers are hidden while a round is played,
+// and evaluated numbers are shown as the game's reveal policy allows.
+func (g *Game) project(snapshot game.Snapshot, viewer string) stateMessage {
+	lastRound := snapshot.LastRound.Project(snapshot.Options.Reveal, viewer)
+	state := stateMessage{
+		Type:      "state",
+		ID:        g.ID,
+		State:     snapshot.State,
+		Phase:     snapshot.Phase,
+		Round:     snapshot.Round,
+		Options:   g.Options,
+		Range:     snapshot.Range,
+		Players:   make(map[string]playerView, len(snapshot.Players)),
+		LastRound: lastRound,
+		Deadline:  snapshot.Deadline,
+		Standings: snapshot.Standings,
+	}
+	for _, p := range snapshot.Players {
+		number := p.Number
+		if p.ID != viewer {
+			number = nil
+			if lastRound != nil && snapshot.State != game.Playing {
+				if n, ok := lastRound.Numbers[p.ID]; ok {
+					number = &n
+				}
 			}
 		}
-
-		if allPlayed {
-			g.resolveRound()
-			g.State = "roundEnd"
+		view := playerView{
+			ID:              p.ID,
+			Name:            p.Name,
+			Lives:           p.Lives,
+			Number:          number,
+			HasPlayed:       p.HasSubmitted,
+			Locked:          p.Locked,
+			Wagered:         p.Wagered,
+			IsHost:          p.IsHost,
+			Team:            p.Team,
+			CardCount:       len(p.Cards),
+			EliminatedRound: p.EliminatedRound,
+		}
+		if p.ID == viewer {
+			view.Cards, view.Play = p.Cards, p.Play
 		}
+		state.Players[p.ID] = view
 	}
+	return state
 }
 
-// resolveRound applies game rules after all players have submitted their numbers.
-func (g *Game) resolveRound() {
-	// Collect all submitted numbers.
-	numbers := make([]int, 0)
-	counts := make(map[int]int)
-	playerNumbers := make(map[string]int) // playerID -> number
-
-	for _, p := range g.Players {
-		if p.Lives > 0 && p.HasPlayed {
-			numbers = append(numbers, p.Number)
-			counts[p.Number]++
-			playerNumbers[p.ID] = p.Number
-		}
-	}
+// broadcast sends the current game state to all connected players.
+func (g *Game) broadcast() {
+	snapshot := g.engine.Snapshot()
 
-	if len(numbers) == 0 {
-		return
+	g.mu.Lock()
+	g.active = time.Now()
+	g.changed()
+	clients := make(map[string]*client, len(g.clients))
+	for id, c := range g.clients {
+		clients[id] = c
 	}
+	g.mu.Unlock()
 
-	// Determine min and max.
-	min := numbers[0]
-	max := numbers[0]
-	for _, num := range numbers {
-		if num < min {
-			min = num
-		}
-		if num > max {
-			max = num
-		}
+	if g.Options.Public {
+		lobbies.notify()
 	}
-
-	// Identify players with min and max numbers.
-	minPlayers := []string{}
-	maxPlayers := []string{}
-	for pid, num := range playerNumbers {
-		if num == min {
-			minPlayers = append(minPlayers, pid)
-		}
-		if num == max {
-			maxPlayers = append(maxPlayers, pid)
+	g.recordRatings(snapshot)
+	g.setRoundTimer(snapshot)
+
+	for id, c := range clients {
+		if err := c.send(g.project(snapshot, id)); err != nil {
+			log.Printf("Error broadcasting to player %s: %v", id, err)
+			c.conn.Close()
+			g.mu.Lock()
+			delete(g.clients, id)
+			g.mu.Unlock()
 		}
 	}
+}
 
-	// Reduce lives for players with min and max numbers.
-	for _, pid := range minPlayers {
-		if player, exists := g.Players[pid]; exists && player.Lives > 0 {
-			player.Lives--
-			if player.Lives <= 0 {
-				// Player is eliminated.
-				player.Lives = 0
-			}
-		}
+// setRoundTimer ends the current round at its deadline, if it has one. It
+// is safe to call after every change: each round gets a single timer.
+func (g *Game) setRoundTimer(snapshot game.Snapshot) {
+	if snapshot.Deadline == nil {
+		return
 	}
-
-	for _, pid := range maxPlayers {
-		if player, exists := g.Players[pid]; exists && player.Lives > 0 {
-			player.Lives--
-			if player.Lives <= 0 {
-				// Player is eliminated.
-				player.Lives = 0
-			}
-		}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.timed == snapshot.Round {
+		return
 	}
+	g.timed = snapshot.Round
 
-	// Handle "Mismo" situations: exactly two players with the same number.
-	for num, cnt := range counts {
-		if cnt == 2 {
-			for pid, pnum := range playerNumbers {
-				if pnum == num {
-					if player, exists := g.Players[pid]; exists && player.Lives > 0 {
-						player.Lives = 0 // Eliminated
-					}
-				}
-			}
+	round := snapshot.Round
+	time.AfterFunc(time.Until(*snapshot.Deadline), func() {
+		if g.engine.EvaluateRound(round) {
+			g.broadcast()
 		}
-	}
+	})
+}
 
-	// Check if the game is over.
-	activePlayers := 0
-	for _, p := range g.Players {
-		if p.Lives > 0 {
-			activePlayers++
+// addPlayer adds a new player to the game and returns their ID. Players
+// joining with an account are rated under it; an account holds one seat per
+// game. Players joining over a WebSocket pass their connection to receive
+// broadcasts.
+func (g *Game) addPlayer(name, account string, c *client) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, a := range g.accounts {
+		if account != "" && a == account {
+			return "", errAccountInGame
 		}
 	}
-	if activePlayers <= 1 {
-		g.State = "gameOver"
+	player := game.NewPlayer(uuid.New().String(), name, false)
+	if err := g.engine.AddPlayer(player); err != nil {
+		return "", err
+	}
+	if account != "" {
+		g.accounts[player.ID] = account
 	}
+	if c != nil {
+		g.clients[player.ID] = c
+	}
+	return player.ID, nil
+}
+
+// isNameError reports whether err is a rejected player name.
+func isNameError(err error) bool {
+	return errors.Is(err, game.ErrEmptyName) || errors.Is(err, game.ErrNameTooLong) || errors.Is(err, game.ErrNameNotAllowed)
 }
 
-// startNextRound prepares the game for the next round.
-func (g *Game) startNextRound() {
+// attach routes broadcasts for a player to a connection.
+func (g *Game) attach(playerID string, c *client) {
 	g.mu.Lock()
 	defer g.mu.Unlock()
+	g.clients[playerID] = c
+}
 
-	if g.State != "roundEnd" && g.State != "gameOver" {
-		return
+// isHost reports whether the player hosts the game.
+func (g *Game) isHost(playerID string) bool {
+	for _, p := range g.engine.Snapshot().Players {
+		if p.ID == playerID {
+			return p.IsHost
+		}
 	}
+	return false
+}
 
-	// Reset players' submission status.
-	for _, p := range g.Players {
-		if p.Lives > 0 {
-			p.HasPlayed = false
-			p.Number = 0
+// hasPlayer reports whether the player is in the game.
+func (g *Game) hasPlayer(playerID string) bool {
+	for _, p := range g.engine.Snapshot().Players {
+		if p.ID == playerID {
+			return true
 		}
 	}
+	return false
+}
 
-	if g.State != "gameOver" {
-		g.Round++
-		g.State = "playing"
+// checkAccess verifies the password or invite token needed to join a
+// private game.
+func (g *Game) checkAccess(password, invite string) error {
+	if !g.Options.Private {
+		return nil
 	}
+	if invite != "" {
+		if err := signer.VerifyInvite(invite, g.ID); err != nil {
+			if errors.Is(err, auth.ErrExpiredToken) {
+				return errors.New("Invite has expired.")
+			}
+			return errors.New("Invalid invite.")
+		}
+		return nil
+	}
+	if g.passwordHash == "" || !auth.CheckPassword(g.passwordHash, password) {
+		return errors.New("Invalid password.")
+	}
+	return nil
 }
 
-// createGameHandler handles the creation of a new game.
-func createGameHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Invalid request method.", http.StatusMethodNotAllowed)
+// leave removes a disconnected player, dropping the game once it is empty.
+func (g *Game) leave(playerID string) {
+	if playerID == "" {
 		return
 	}
+	g.mu.Lock()
+	delete(g.clients, playerID)
+	g.mu.Unlock()
 
-	game := createGame()
-
-	gamesMu.Lock()
-	games[game.ID] = game
-	gamesMu.Unlock()
+	if g.engine.RemovePlayer(playerID) == 0 {
+		unregisterGame(g.ID)
+	}
+	g.broadcast()
+}
 
-	response := map[string]string{
-		"gameId": game.ID,
+// detach stops sending broadcasts to a connection without removing the
+// player, for players who joined through the REST API.
+func (g *Game) detach(playerID string, c *client) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.clients[playerID] == c {
+		delete(g.clients, playerID)
 	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
 }
 
-// wsHandler manages WebSocket connections for a specific game.
-func wsHandler(w http.ResponseWriter, r *http.Request) {
-	// Extract game ID from the URL.
-	gameID := r.URL.Path[len("/ws/game/"):]
+// createRequest is the body of a request to create a game.
+type createRequest struct {
+	Public     bool   `json:"public,omitempty"`
+	Private    bool   `json:"private,omitempty"`
+	Password   string `json:"password,omitempty"`
+	Lives      int    `json:"lives,omitempty"`
+	MinPlayers int    `json:"minPlayers,omitempty"`
+	// Mode picks the round rule, and WinnerGainsLife rewards the winner of
+	// a mode that has one instead of penalising everyone else.
+	Mode            game.Mode `json:"mode,omitempty"`
+	WinnerGainsLife bool      `json:"winnerGainsLife,omitempty"`
+	// BeautyFactor is the p of a beauty contest, 2/3 by default.
+	BeautyFactor *game.Fraction `json:"beautyFactor,omitempty"`
+	// Teams splits the players into teams sharing their lives.
+	Teams int `json:"teams,omitempty"`
+	// CardEvery deals a card to every player every CardEvery rounds.
+	CardEvery int `json:"cardEvery,omitempty"`
+	// Reveal is how much of each round's numbers players see.
+	Reveal game.Reveal `json:"reveal,omitempty"`
+	// Wagers let players stake a life on a round, and MedianBonus gives a
+	// life back to the exact median picker.
+	Wagers      bool `json:"wagers,omitempty"`
+	MedianBonus bool `json:"medianBonus,omitempty"`
+	// MinNumber and MaxNumber override the range of numbers players may
+	// submit.
+	MinNumber *game.Number `json:"minNumber,omitempty"`
+	MaxNumber *game.Number `json:"maxNumber,omitempty"`
+	// Endgame switches to another rule once EndgamePlayers (default 2)
+	// players are left.
+	Endgame        game.Endgame `json:"endgame,omitempty"`
+	EndgamePlayers int          `json:"endgamePlayers,omitempty"`
+	// Ties replace the default tie rules, game.ClassicTieRules.
+	Ties *game.TieRules `json:"ties,omitempty"`
+	// LockIn lets players change their number until they lock it.
+	LockIn bool `json:"lockIn,omitempty"`
+	// RoundSeconds ends each round after that many seconds, at most an
+	// hour; players who have not submitted a number by then lose a life.
+	RoundSeconds int `json:"roundSeconds,omitempty"`
+	// ShrinkWidths narrow the range each round, centred on the previous
+	// round's median with ShrinkToMedian.
+	ShrinkWidths   []game.Number `json:"shrinkWidths,omitempty"`
+	ShrinkToMedian bool          `json:"shrinkToMedian,omitempty"`
+}
 
-	gamesMu.Lock()
-	game, exists := games[gameID]
-	gamesMu.Unlock()
+// create validates a create request and creates the game. It returns the
+// game and, for private games, an invite token.
+func (req createRequest) create() (*Game, string, error) {
+	opts := defaultOptions()
+	opts.Public = req.Public
+	opts.Private = req.Private || req.Password != ""
+	if req.Lives > 0 {
+		opts.Lives = req.Lives
+	}
+	if req.MinPlayers > 0 {
+		opts.MinPlayers = req.MinPlayers
+	}
+	if req.MinNumber != nil {
+		opts.MinNumber = *req.MinNumber
+	}
+	if req.MaxNumber != nil {
+		opts.MaxNumber = *req.MaxNumber
+	}
+	if req.Ties != nil {
+		opts.Ties = *req.Ties
+	}
+	opts.Mode = req.Mode
+	opts.WinnerGainsLife = req.WinnerGainsLife
+	opts.BeautyFactor = req.BeautyFactor
+	opts.Teams = req.Teams
+	opts.CardEvery = req.CardEvery
+	opts.Reveal = req.Reveal
+	opts.Wagers = req.Wagers
+	opts.MedianBonus = req.MedianBonus
+	if opts.Mode == game.ModeBeautyContest && opts.BeautyFactor == nil {
+		factor := game.DefaultBeautyFactor
+		opts.BeautyFactor = &factor
+	}
+	opts.LockIn = req.LockIn
+	opts.RoundSeconds = req.RoundSeconds
+	opts.ShrinkWidths = req.ShrinkWidths
+	opts.ShrinkToMedian = req.ShrinkToMedian
+	opts.Endgame = req.Endgame
+	if opts.Endgame != game.EndgameNone {
+		opts.EndgamePlayers = 2
+		if req.EndgamePlayers > 0 {
+			opts.EndgamePlayers = req.EndgamePlayers
+		}
+	}
+	if opts.Public && opts.Private {
+		return nil, "", errPublicPrivate
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, "", err
+	}
 
-	if !exists {
-		http.Error(w, "Game not found.", http.StatusNotFound)
-		return
+	var passwordHash string
+	if req.Password != "" {
+		hash, err := auth.HashPassword(req.Password)
+		if err != nil {
+			return nil, "", err
+		}
+		passwordHash = hash
 	}
 
-	conn, err := upgrader.Upgrade(w, r, nil)
+	g, err := newGame(opts, passwordHash)
 	if err != nil {
-		log.Printf("WebSocket Upgrade Error: %v", err)
-		return
+		return nil, "", err
+	}
+	if opts.Public {
+		lobbies.notify()
 	}
-	defer conn.Close()
-
-	var player *Player
-
-	for {
-		var msg map[string]interface{}
-		if err := conn.ReadJSON(&msg); err != nil {
-			log.Printf("WebSocket Read Error: %v", err)
-			if player != nil {
-				game.removePlayer(player.ID)
-				game.broadcast()
-			}
-			break
-		}
 
-		switch msg["type"] {
-		case "join":
-			name, ok := msg["name"].(string)
-			if !ok || name == "" {
-				conn.WriteJSON(map[string]string{"error": "Invalid name."})
-				continue
-			}
-			player = game.addPlayer(name, conn)
-			game.broadcast()
+	if !opts.Private {
+		return g, "", nil
+	}
+	invite, _, err := signer.NewInvite(g.ID, inviteTTL)
+	if err != nil {
+		return nil, "", err
+	}
+	return g, invite, nil
+}
 
-		case "start":
-			if player == nil || !player.IsHost {
-				conn.WriteJSON(map[string]string{"error": "Only host can start the game."})
-				continue
-			}
-			game.mu.Lock()
-			if game.State != "waiting" {
-				game.mu.Unlock()
-				conn.WriteJSON(map[string]string{"error": "Game already started."})
-				continue
-			}
-			if len(game.Players) < 3 {
-				game.mu.Unlock()
-				conn.WriteJSON(map[string]string{"error": "At least 3 players required to start."})
-				continue
-			}
-			game.State = "playing"
-			game.mu.Unlock()
-			game.broadcast()
-
-		case "number":
-			if player == nil || game.State != "playing" || player.Lives <= 0 {
-				conn.WriteJSON(map[string]string{"error": "Cannot submit number at this time."})
-				continue
-			}
-			// Parse the number.
-			numberFloat, ok := msg["number"].(float64)
-			if !ok {
-				conn.WriteJSON(map[string]string{"error": "Invalid number."})
-				continue
-			}
-			number := int(numberFloat)
-			if number < 0 {
-				conn.WriteJSON(map[string]string{"error": "Number cannot be negative."})
-				continue
-			}
-			game.submitNumber(player.ID, number)
-			game.broadcast()
+// createGameResponse is the body returned by createGameHandler.
+type createGameResponse struct {
+	GameID string `json:"gameId"`
+	Invite string `json:"invite,omitempty"`
+}
 
-		case "nextRound":
-			if player == nil || !player.IsHost {
-				conn.WriteJSON(map[string]string{"error": "Only host can start the next round."})
-				continue
-			}
-			game.startNextRound()
-			game.broadcast()
+// createGameHandler handles the creation of a new game.
+func createGameHandler(w http.ResponseWriter, r *http.Request) {
+	var req createRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		http.Error(w, "Invalid request body.", http.StatusBadRequest)
+		return
+	}
 
-		default:
-			conn.WriteJSON(map[string]string{"error": "Unknown message type."})
-		}
+	g, invite, err := req.create()
+	switch {
+	case err == errPublicPrivate || errors.Is(err, game.ErrInvalidOptions):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err == errServerFull:
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	case err != nil:
+		log.Printf("Error creating game: %v", err)
+		http.Error(w, "Failed to create game.", http.StatusInternalServerError)
+		return
 	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(createGameResponse{GameID: g.ID, Invite: invite})
 }
 
 func main() {
+	var err error
+	signer, err = auth.NewSignerFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to initialise token signer: %v", err)
+	}
+
 	// Serve static files from the "static" directory.
 	fs := http.FileServer(http.Dir("./build"))
 	http.Handle("/", fs)
 
 	// API Endpoints
-	http.HandleFunc("/create-game", createGameHandler)
-	http.HandleFunc("/ws/game/", wsHandler)
+	registerAPI(http.DefaultServeMux)
+	registerChannels(http.DefaultServeMux)
 
 	log.Println("Server starting on http://localhost:8080")
-	log.Fatal(http.ListenAndServe(":8080", nil))
+	origins.Reject = rejectCrossOrigin
+	handler := origins.Protect(ratelimit.MaxBytes(maxBodySize, http.DefaultServeMux))
+	log.Fatal(http.ListenAndServe(":8080", handler))
 }
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"mismo/auth"
+	"mismo/ratelimit"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestMain(m *testing.M) {
+	signer = auth.NewSigner([]byte("test secret"))
+	// Every test client shares one address.
+	createLimiter = ratelimit.NewLimiter(1000, 1000)
+	joinLimiter = ratelimit.NewLimiter(1000, 1000)
+	os.Exit(m.Run())
+}
+
+// testServer serves the API and WebSocket channels.
+func testServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	registerAPI(mux)
+	registerChannels(mux)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// testGame creates a game that is dropped when the test ends.
+func testGame(t *testing.T, opts Options) *Game {
+	t.Helper()
+	g, err := newGame(opts, "")
+	if err != nil {
+		t.Fatalf("newGame: %v", err)
+	}
+	t.Cleanup(func() { unregisterGame(g.ID) })
+	return g
+}
+
+// dialGame opens a WebSocket to a game.
+func dialGame(t *testing.T, server *httptest.Server, gameID string) *websocket.Conn {
+	t.Helper()
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws/game/" + gameID
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("dialing %s: %v", url, err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// readMessage reads messages until one of the given type arrives, failing
+// on an error message.
+func readMessage(t *testing.T, conn *websocket.Conn, typ string) []byte {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("waiting for %q: %v", typ, err)
+		}
+		var msg struct {
+			Type  string `json:"type"`
+			Error string `json:"error"`
+		}
+		json.Unmarshal(data, &msg)
+		if msg.Error != "" {
+			t.Fatalf("waiting for %q: server sent error %q", typ, msg.Error)
+		}
+		if msg.Type == typ {
+			return data
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Package ratelimit provides token buckets to throttle clients.
+package ratelimit
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often a Limiter forgets clients that have been idle
+// long enough for their bucket to refill.
+const sweepInterval = time.Minute
+
+// Bucket is a token bucket: it holds up to burst tokens and regains rate
+// tokens per second. Each allowed event takes one token.
+type Bucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	mu     sync.Mutex
+}
+
+func NewBucket(rate float64, burst int) *Bucket {
+	return &Bucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Allow takes a token if one is available.
+func (b *Bucket) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// full reports whether the bucket has refilled completely.
+func (b *Bucket) full(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.tokens+now.Sub(b.last).Seconds()*b.rate >= b.burst
+}
+
+// Limiter keeps one bucket per key, such as a client IP.
+type Limiter struct {
+	rate      float64
+	burst     int
+	buckets   map[string]*Bucket
+	lastSweep time.Time
+	mu        sync.Mutex
+}
+
+// NewLimiter allows burst events per key at once and rate events per second
+// after that.
+func NewLimiter(rate float64, burst int) *Limiter {
+	return &Limiter{
+		rate:      rate,
+		burst:     burst,
+		buckets:   make(map[string]*Bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow takes a token from the bucket of key.
+func (l *Limiter) Allow(key string) bool {
+	l.mu.Lock()
+	now := time.Now()
+	if now.Sub(l.lastSweep) > sweepInterval {
+		for k, b := range l.buckets {
+			if b.full(now) {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastSweep = now
+	}
+	b, ok := l.buckets[key]
+	if !ok {
+		b = NewBucket(l.rate, l.burst)
+		l.buckets[key] = b
+	}
+	l.mu.Unlock()
+
+	return b.Allow()
+}
+
+// RetryAfter is roughly how long a throttled client should wait for a token.
+func (l *Limiter) RetryAfter() time.Duration {
+	return time.Duration(float64(time.Second) / l.rate)
+}
+
+// Limit rejects requests with 429 Too Many Requests once the client's IP runs
+// out of tokens.
+func (l *Limiter) Limit(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !l.Allow(ClientIP(r)) {
+			TooManyRequests(w, l.RetryAfter())
+			return
+		}
+		next(w, r)
+	}
+}
+
+// TooManyRequests writes a 429 response asking the client to retry later.
+func TooManyRequests(w http.ResponseWriter, retryAfter time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	http.Error(w, "Too many requests", http.StatusTooManyRequests)
+}
+
+// MaxBytes caps the size of request bodies; reading past n bytes fails.
+func MaxBytes(n int64, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, n)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ClientIP returns the IP address of the client. Forwarding headers are
+// ignored since they can be set by the client.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
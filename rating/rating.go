@@ -0,0 +1,108 @@
+// Package rating implements Glicko ratings for multiplayer Mismo games.
+//
+// A finished game is treated as one rating period in which every player
+// played every other player: finishing ahead of someone counts as a win,
+// behind as a loss, and being eliminated in the same round as a draw.
+package rating
+
+import (
+	"math"
+)
+
+const (
+	DefaultValue     = 1500.0
+	DefaultDeviation = 350.0
+	MinDeviation     = 30.0
+
+	// deviationGrowth is the uncertainty a rating regains before each game,
+	// so that inactive or fast-improving players can still move.
+	deviationGrowth = 35.0
+)
+
+var q = math.Ln10 / 400
+
+// Rating is a Glicko rating with its deviation.
+type Rating struct {
+	Value     float64 `json:"value"`
+	Deviation float64 `json:"deviation"`
+}
+
+// Default returns the rating given to accounts that have not played yet.
+func Default() Rating {
+	return Rating{Value: DefaultValue, Deviation: DefaultDeviation}
+}
+
+// impact reduces the weight of an opponent with an uncertain rating.
+func impact(deviation float64) float64 {
+	return 1 / math.Sqrt(1+3*q*q*deviation*deviation/(math.Pi*math.Pi))
+}
+
+// Expected returns the expected score of r against opponent, between 0 and 1.
+func (r Rating) Expected(opponent Rating) float64 {
+	return 1 / (1 + math.Pow(10, -impact(opponent.Deviation)*(r.Value-opponent.Value)/400))
+}
+
+// outcome is the result of one pairing within a game.
+type outcome struct {
+	opponent Rating
+	score    float64
+}
+
+// update applies one rating period to r.
+func (r Rating) update(outcomes []outcome) Rating {
+	deviation := math.Min(math.Sqrt(r.Deviation*r.Deviation+deviationGrowth*deviationGrowth), DefaultDeviation)
+	if len(outcomes) == 0 {
+		return Rating{Value: r.Value, Deviation: deviation}
+	}
+
+	pre := Rating{Value: r.Value, Deviation: deviation}
+	var variance, delta float64
+	for _, o := range outcomes {
+		g := impact(o.opponent.Deviation)
+		e := pre.Expected(o.opponent)
+		variance += g * g * e * (1 - e)
+		delta += g * (o.score - e)
+	}
+	dSquared := 1 / (q * q * variance)
+	denominator := 1/(deviation*deviation) + 1/dSquared
+
+	return Rating{
+		Value:     r.Value + q/denominator*delta,
+		Deviation: math.Max(math.Sqrt(1/denominator), MinDeviation),
+	}
+}
+
+// Update computes new ratings from a finishing order. Standings are groups of
+// account IDs, best first; accounts in the same group finished tied. All
+// pairings are evaluated against the ratings as they were before the game.
+func Update(before map[string]Rating, standings [][]string) map[string]Rating {
+	after := make(map[string]Rating)
+	for place, group := range standings {
+		for _, account := range group {
+			var outcomes []outcome
+			for otherPlace, otherGroup := range standings {
+				score := 0.5
+				if place < otherPlace {
+					score = 1
+				} else if place > otherPlace {
+					score = 0
+				}
+				for _, other := range otherGroup {
+					if other == account {
+						continue
+					}
+					outcomes = append(outcomes, outcome{opponent: lookup(before, other), score: score})
+				}
+			}
+			after[account] = lookup(before, account).update(outcomes)
+		}
+	}
+	return after
+}
+
+func lookup(ratings map[string]Rating, account string) Rating {
+	if r, ok := ratings[account]; ok {
+		return r
+	}
+	return Default()
+}
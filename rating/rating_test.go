@@ -0,0 +1,121 @@
+package rating
+
+import (
+	"math"
+	"testing"
+)
+
+// near reports whether two ratings agree to a tenth of a point.
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 0.1
+}
+
+func TestUpdate(t *testing.T) {
+	veteran := Rating{Value: 1500, Deviation: MinDeviation}
+	tests := []struct {
+		name      string
+		before    map[string]Rating
+		standings [][]string
+		want      map[string]Rating
+	}{
+		{
+			name:      "new players, one win",
+			standings: [][]string{{"a"}, {"b"}},
+			want:      map[string]Rating{"a": {1662.3, 290.3}, "b": {1337.7, 290.3}},
+		},
+		{
+			name:      "new players tied",
+			standings: [][]string{{"a", "b"}},
+			want:      map[string]Rating{"a": {1500, 290.3}, "b": {1500, 290.3}},
+		},
+		{
+			name:      "three places",
+			standings: [][]string{{"a"}, {"b"}, {"c"}},
+			want:      map[string]Rating{"a": {1747.2, 253.3}, "b": {1500, 253.3}, "c": {1252.8, 253.3}},
+		},
+		{
+			name:      "an upset",
+			before:    map[string]Rating{"a": {1800, 100}, "b": {1400, 100}},
+			standings: [][]string{{"b"}, {"a"}},
+			want:      map[string]Rating{"a": {1746.2, 104.4}, "b": {1453.8, 104.4}},
+		},
+		{
+			name:      "settled ratings regain some uncertainty but move little",
+			before:    map[string]Rating{"a": veteran, "b": veteran},
+			standings: [][]string{{"a"}, {"b"}},
+			want:      map[string]Rating{"a": {1506.0, 45.7}, "b": {1494.0, 45.7}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Update(tt.before, tt.standings)
+			if len(got) != len(tt.want) {
+				t.Fatalf("rated %d accounts, want %d", len(got), len(tt.want))
+			}
+			for account, want := range tt.want {
+				r := got[account]
+				if !near(r.Value, want.Value) || !near(r.Deviation, want.Deviation) {
+					t.Errorf("%s = %.1f ± %.1f, want %.1f ± %.1f", account, r.Value, r.Deviation, want.Value, want.Deviation)
+				}
+				if r.Deviation < MinDeviation || r.Deviation > DefaultDeviation {
+					t.Errorf("%s deviation %.1f is out of range", account, r.Deviation)
+				}
+			}
+		})
+	}
+}
+
+func TestQuality(t *testing.T) {
+	tests := []struct {
+		name     string
+		ratings  map[string]Rating
+		accounts []string
+		score    float64
+		expected map[string]float64
+	}{
+		{"nobody", nil, nil, 1, map[string]float64{}},
+		{"one account", nil, []string{"a"}, 1, map[string]float64{}},
+		{"new accounts", nil, []string{"a", "b"}, 1, map[string]float64{"a": 0.5, "b": 0.5}},
+		{
+			"lopsided pair",
+			map[string]Rating{"a": {1900, 30}, "b": {1500, 30}},
+			[]string{"a", "b"},
+			0.1835,
+			map[string]float64{"a": 0.9082, "b": 0.0918},
+		},
+		{
+			"unrated account counts as new",
+			map[string]Rating{"a": {1500, 350}},
+			[]string{"a", "b"},
+			1,
+			map[string]float64{"a": 0.5, "b": 0.5},
+		},
+		{
+			"three accounts average their pairings",
+			map[string]Rating{"a": {1900, 30}, "b": {1500, 30}, "c": {1500, 30}},
+			[]string{"a", "b", "c"},
+			(0.1835 + 0.1835 + 1) / 3,
+			map[string]float64{"a": 0.9082, "b": (0.0918 + 0.5) / 2, "c": (0.0918 + 0.5) / 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewStore()
+			for account, r := range tt.ratings {
+				s.ratings[account] = r
+			}
+			got := s.Quality(tt.accounts)
+			if math.Abs(got.Score-tt.score) > 0.001 {
+				t.Errorf("score = %.4f, want %.4f", got.Score, tt.score)
+			}
+			if len(got.Expected) != len(tt.expected) {
+				t.Errorf("expected scores for %d accounts, want %d", len(got.Expected), len(tt.expected))
+			}
+			for account, want := range tt.expected {
+				if math.Abs(got.Expected[account]-want) > 0.001 {
+					t.Errorf("expected[%s] = %.4f, want %.4f", account, got.Expected[account], want)
+				}
+			}
+		})
+	}
+}
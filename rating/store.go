@@ -0,0 +1,157 @@
+package rating
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"mismo/game"
+)
+
+// Entry is one game in an account's rating history.
+type Entry struct {
+	GameID  string    `json:"gameId"`
+	Time    time.Time `json:"time"`
+	Place   int       `json:"place"`
+	Players int       `json:"players"`
+	Before  Rating    `json:"before"`
+	After   Rating    `json:"after"`
+}
+
+// Quality describes how balanced a prospective table is.
+type Quality struct {
+	// Score is 1 when every pairing is a coin flip and tends to 0 as the
+	// table becomes lopsided.
+	Score float64 `json:"score"`
+	// Expected is each account's average expected score against the others.
+	Expected map[string]float64 `json:"expected"`
+}
+
+// Store keeps the current rating and rating history of every account.
+type Store struct {
+	ratings map[string]Rating
+	history map[string][]Entry
+	mu      sync.Mutex
+}
+
+func NewStore() *Store {
+	return &Store{
+		ratings: make(map[string]Rating),
+		history: make(map[string][]Entry),
+	}
+}
+
+// Rating returns the current rating of an account.
+func (s *Store) Rating(account string) Rating {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return lookup(s.ratings, account)
+}
+
+// History returns the rating history of an account, oldest first.
+func (s *Store) History(account string) []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Entry(nil), s.history[account]...)
+}
+
+// Record applies the result of a finished game. Standings are groups of
+// account IDs, best first, with tied accounts sharing a group.
+func (s *Store) Record(gameID string, standings [][]string) error {
+	seen := make(map[string]bool)
+	players := 0
+	for _, group := range standings {
+		for _, account := range group {
+			if seen[account] {
+				return errors.New("account appears more than once in standings")
+			}
+			seen[account] = true
+			players++
+		}
+	}
+	if players < 2 {
+		return errors.New("at least 2 accounts are required to rate a game")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	after := Update(s.ratings, standings)
+	place := 1
+	for _, group := range standings {
+		for _, account := range group {
+			s.history[account] = append(s.history[account], Entry{
+				GameID:  gameID,
+				Time:    now,
+				Place:   place,
+				Players: players,
+				Before:  lookup(s.ratings, account),
+				After:   after[account],
+			})
+		}
+		place += len(group)
+	}
+	for account, r := range after {
+		s.ratings[account] = r
+	}
+	return nil
+}
+
+// RecordGame applies the final standings of a finished game. The account
+// function maps a placed player to the account that is rated; players mapped
+// to "" are left out.
+func (s *Store) RecordGame(gameID string, standings *game.Standings, account func(game.Standing) string) error {
+	if standings == nil {
+		return errors.New("game is not finished")
+	}
+
+	var groups [][]string
+	place := 0
+	for _, st := range standings.Places {
+		a := account(st)
+		if a == "" {
+			continue
+		}
+		if len(groups) == 0 || st.Place != place {
+			groups = append(groups, nil)
+			place = st.Place
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], a)
+	}
+	return s.Record(gameID, groups)
+}
+
+// Quality reports how evenly matched the given accounts would be at one table.
+func (s *Store) Quality(accounts []string) Quality {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	quality := Quality{Expected: make(map[string]float64)}
+	if len(accounts) < 2 {
+		quality.Score = 1
+		return quality
+	}
+
+	var total float64
+	pairs := 0
+	for i, a := range accounts {
+		ra := lookup(s.ratings, a)
+		var expected float64
+		for j, b := range accounts {
+			if i == j {
+				continue
+			}
+			e := ra.Expected(lookup(s.ratings, b))
+			expected += e
+			if i < j {
+				total += 1 - math.Abs(2*e-1)
+				pairs++
+			}
+		}
+		quality.Expected[a] = expected / float64(len(accounts)-1)
+	}
+	quality.Score = total / float64(pairs)
+	return quality
+}
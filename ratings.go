@@ -0,0 +1,117 @@
+// ratings.go
+package main
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"mismo/game"
+	"mismo/rating"
+)
+
+// Every finished game updates the ratings of the players who joined it with
+// an account token from POST /api/v1/accounts. Names are free text and
+// prove nothing, so players without an account are left out, and a game is
+// only rated when at least two accounts took part.
+
+// maxQualityAccounts caps the table a quality report is asked for.
+const maxQualityAccounts = 32
+
+var ratings = rating.NewStore()
+
+// accountResponse is the body returned when an account is created.
+type accountResponse struct {
+	Account string `json:"account"`
+	Token   string `json:"token"`
+}
+
+// verifyAccount returns the account an account token proves, or "" for
+// players joining without one.
+func verifyAccount(token string) (string, error) {
+	if token == "" {
+		return "", nil
+	}
+	account, err := signer.VerifyAccountToken(token)
+	if err != nil {
+		return "", errInvalidAccount
+	}
+	return account, nil
+}
+
+// apiCreateAccount creates an account to be rated under. The token is the
+// only proof of ownership: keep it, there is no way to recover it.
+func apiCreateAccount(w http.ResponseWriter, r *http.Request) {
+	account := uuid.New().String()
+	token, err := signer.NewAccountToken(account, accountTTL)
+	if err != nil {
+		writeEngineError(w, err)
+		return
+	}
+	writeAPIJSON(w, http.StatusCreated, accountResponse{Account: account, Token: token})
+}
+
+// recordRatings rates a game once it is finished. It is safe to call after
+// every change: a game is only rated once.
+func (g *Game) recordRatings(snapshot game.Snapshot) {
+	if snapshot.Standings == nil {
+		return
+	}
+	g.mu.Lock()
+	rated := g.rated
+	g.rated = true
+	accounts := make(map[string]string, len(g.accounts))
+	for id, a := range g.accounts {
+		accounts[id] = a
+	}
+	g.mu.Unlock()
+	if rated || len(accounts) < 2 {
+		return
+	}
+
+	err := ratings.RecordGame(g.ID, snapshot.Standings, func(s game.Standing) string {
+		return accounts[s.ID]
+	})
+	if err != nil {
+		log.Printf("Error rating game %s: %v", g.ID, err)
+	}
+}
+
+// ratingResponse is an account's current rating and the games behind it.
+type ratingResponse struct {
+	Account string         `json:"account"`
+	Rating  rating.Rating  `json:"rating"`
+	History []rating.Entry `json:"history"`
+}
+
+type qualityRequest struct {
+	Accounts []string `json:"accounts"`
+}
+
+func apiGetRating(w http.ResponseWriter, r *http.Request) {
+	account := r.PathValue("account")
+	history := ratings.History(account)
+	if history == nil {
+		history = []rating.Entry{}
+	}
+	writeAPIJSON(w, http.StatusOK, ratingResponse{
+		Account: account,
+		Rating:  ratings.Rating(account),
+		History: history,
+	})
+}
+
+// apiMatchQuality reports how evenly matched {"accounts"} would be at one
+// table.
+func apiMatchQuality(w http.ResponseWriter, r *http.Request) {
+	var req qualityRequest
+	if !decodeAPIBody(w, r, &req) {
+		return
+	}
+	if len(req.Accounts) > maxQualityAccounts {
+		writeAPIError(w, http.StatusBadRequest, "too_many_accounts", "too many accounts")
+		return
+	}
+	writeAPIJSON(w, http.StatusOK, ratings.Quality(req.Accounts))
+}
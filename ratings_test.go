@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"mismo/game"
+)
+
+func TestJoinWithAccount(t *testing.T) {
+	server := testServer(t)
+	g := testGame(t, defaultOptions())
+	token, err := signer.NewAccountToken("acct-ana", accountTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	playerToken, err := signer.NewPlayerToken(g.ID, "someone", tokenTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		account string
+		status  int
+		code    string
+	}{
+		{"with an account", token, http.StatusCreated, ""},
+		{"same account again", token, http.StatusConflict, "account_in_game"},
+		{"forged account", "acct-ana.bad", http.StatusUnauthorized, "invalid_account"},
+		{"player token as account", playerToken, http.StatusUnauthorized, "invalid_account"},
+		{"without an account", "", http.StatusCreated, ""},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(joinRequest{Name: "p" + string(rune('a'+i)), Account: tt.account})
+			resp, err := http.Post(server.URL+"/api/v1/games/"+g.ID+"/players", "application/json", bytes.NewReader(body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+			if tt.code != "" {
+				var e apiError
+				if err := json.NewDecoder(resp.Body).Decode(&e); err != nil || e.Error.Code != tt.code {
+					t.Errorf("error = %+v, %v, want code %q", e, err, tt.code)
+				}
+			}
+		})
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.accounts) != 1 {
+		t.Errorf("accounts = %v, want only acct-ana", g.accounts)
+	}
+}
+
+func TestRecordRatingsOnlyRatesAccounts(t *testing.T) {
+	standings := &game.Standings{Places: []game.Standing{
+		{ID: "p1", Name: "Ana", Place: 1},
+		{ID: "p2", Name: "Bea", Place: 2},
+		{ID: "p3", Name: "Cy", Place: 3},
+	}}
+	tests := []struct {
+		name     string
+		accounts map[string]string
+		rated    []string
+	}{
+		{"every player has an account", map[string]string{"p1": "acct-1a", "p2": "acct-1b", "p3": "acct-1c"}, []string{"acct-1a", "acct-1b", "acct-1c"}},
+		{"guests are left out", map[string]string{"p1": "acct-2a", "p3": "acct-2c"}, []string{"acct-2a", "acct-2c"}},
+		{"a single account is not rated", map[string]string{"p2": "acct-3b"}, nil},
+		{"no accounts", map[string]string{}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := testGame(t, defaultOptions())
+			g.accounts = tt.accounts
+			g.recordRatings(game.Snapshot{Standings: standings})
+			g.recordRatings(game.Snapshot{Standings: standings})
+
+			for _, account := range tt.accounts {
+				want := 0
+				for _, a := range tt.rated {
+					if a == account {
+						want = 1
+					}
+				}
+				if got := len(ratings.History(account)); got != want {
+					t.Errorf("%s has %d rated games, want %d", account, got, want)
+				}
+			}
+			for _, name := range []string{"Ana", "Bea", "Cy", "ana"} {
+				if got := ratings.History(name); len(got) != 0 {
+					t.Errorf("name %q was rated", name)
+				}
+			}
+		})
+	}
+}
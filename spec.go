@@ -0,0 +1,300 @@
+// spec.go
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"mismo/game"
+)
+
+// The OpenAPI and AsyncAPI documents are generated from apiRoutes and
+// wsChannels, which are also what the server registers and dispatches on,
+// and the schemas are derived from the Go types that are encoded and
+// decoded.
+
+const specVersion = "1"
+
+// specResponse is the body returned by specHandler.
+type specResponse struct {
+	OpenAPI  map[string]interface{} `json:"openapi"`
+	AsyncAPI map[string]interface{} `json:"asyncapi"`
+}
+
+var (
+	spec     specResponse
+	specOnce sync.Once
+)
+
+// specHandler serves both documents.
+func specHandler(w http.ResponseWriter, r *http.Request) {
+	specOnce.Do(func() {
+		spec = specResponse{OpenAPI: openAPI(), AsyncAPI: asyncAPI()}
+	})
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(spec)
+}
+
+// pathParams matches the {name} wildcards of a route.
+var pathParams = regexp.MustCompile(`\{(\w+)\}`)
+
+// openAPI describes the HTTP endpoints as an OpenAPI 3.0 document.
+func openAPI() map[string]interface{} {
+	schemas := newSchemaSet()
+	paths := make(map[string]interface{})
+
+	for _, route := range apiRoutes() {
+		operation := map[string]interface{}{
+			"summary": route.Summary,
+		}
+
+		var params []interface{}
+		for _, match := range pathParams.FindAllStringSubmatch(route.Path, -1) {
+			params = append(params, map[string]interface{}{
+				"name": match[1], "in": "path", "required": true,
+				"schema": map[string]interface{}{"type": "string"},
+			})
+		}
+		if params != nil {
+			operation["parameters"] = params
+		}
+		if route.Auth {
+			operation["security"] = []interface{}{map[string]interface{}{"playerToken": []string{}}}
+		}
+		if route.Request != nil {
+			operation["requestBody"] = map[string]interface{}{
+				"content": jsonContent(schemas.of(reflect.TypeOf(route.Request))),
+			}
+		}
+
+		responses := make(map[string]interface{})
+		success := map[string]interface{}{"description": http.StatusText(route.Status)}
+		switch {
+		case route.Stream:
+			success["content"] = map[string]interface{}{
+				"text/event-stream": map[string]interface{}{"schema": schemas.of(reflect.TypeOf(route.Response))},
+			}
+		case route.Response != nil:
+			success["content"] = jsonContent(schemas.of(reflect.TypeOf(route.Response)))
+		}
+		responses[strconv.Itoa(route.Status)] = success
+		if route.Envelope {
+			responses["default"] = map[string]interface{}{
+				"description": "Error",
+				"content":     jsonContent(schemas.of(reflect.TypeOf(apiError{}))),
+			}
+		} else {
+			responses["default"] = map[string]interface{}{
+				"description": "Error",
+				"content": map[string]interface{}{
+					"text/plain": map[string]interface{}{"schema": map[string]interface{}{"type": "string"}},
+				},
+			}
+		}
+		operation["responses"] = responses
+
+		path, _ := paths[route.Path].(map[string]interface{})
+		if path == nil {
+			path = make(map[string]interface{})
+			paths[route.Path] = path
+		}
+		path[strings.ToLower(route.Method)] = operation
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info":    map[string]interface{}{"title": "Mismo HTTP API", "version": specVersion},
+		"paths":   paths,
+		"components": map[string]interface{}{
+			"schemas": schemas.defs,
+			"securitySchemes": map[string]interface{}{
+				"playerToken": map[string]interface{}{"type": "http", "scheme": "bearer"},
+			},
+		},
+	}
+}
+
+// asyncAPI describes the WebSocket channels as an AsyncAPI 2.6 document.
+// Publish operations are messages clients send, subscribe operations those
+// they receive.
+func asyncAPI() map[string]interface{} {
+	schemas := newSchemaSet()
+	channels := make(map[string]interface{})
+
+	for _, channel := range wsChannels() {
+		item := map[string]interface{}{"description": channel.Summary}
+
+		params := make(map[string]interface{})
+		for _, match := range pathParams.FindAllStringSubmatch(channel.Path, -1) {
+			params[match[1]] = map[string]interface{}{"schema": map[string]interface{}{"type": "string"}}
+		}
+		if len(params) > 0 {
+			item["parameters"] = params
+		}
+		if len(channel.Publish) > 0 {
+			item["publish"] = map[string]interface{}{"message": schemas.messages(channel.Publish)}
+		}
+		if len(channel.Subscribe) > 0 {
+			item["subscribe"] = map[string]interface{}{"message": schemas.messages(channel.Subscribe)}
+		}
+		channels[channel.Path] = item
+	}
+
+	return map[string]interface{}{
+		"asyncapi": "2.6.0",
+		"info":     map[string]interface{}{"title": "Mismo WebSocket API", "version": specVersion},
+		"channels": channels,
+		"components": map[string]interface{}{
+			"schemas": schemas.defs,
+		},
+	}
+}
+
+func jsonContent(schema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"application/json": map[string]interface{}{"schema": schema},
+	}
+}
+
+// messages describes a list of message types. Each payload is inlined so
+// that its "type" property can be pinned to the message's type.
+func (s *schemaSet) messages(list []wsMessage) map[string]interface{} {
+	oneOf := make([]interface{}, 0, len(list))
+	for _, m := range list {
+		t := reflect.TypeOf(m.Payload)
+		payload := s.object(t)
+		if props, ok := payload["properties"].(map[string]interface{}); ok && m.Type != "" {
+			if _, ok := props["type"]; ok {
+				props["type"] = map[string]interface{}{"type": "string", "enum": []string{m.Type}}
+			}
+		}
+		name := m.Type
+		if name == "" {
+			name = "error"
+		}
+		oneOf = append(oneOf, map[string]interface{}{
+			"name":    name,
+			"summary": m.Summary,
+			"payload": payload,
+		})
+	}
+	return map[string]interface{}{"oneOf": oneOf}
+}
+
+// schemaSet builds JSON schemas for Go types, collecting named structs as
+// components referenced by name.
+type schemaSet struct {
+	defs map[string]interface{}
+}
+
+func newSchemaSet() *schemaSet {
+	return &schemaSet{defs: make(map[string]interface{})}
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	numberType   = reflect.TypeOf(game.Number{})
+	fractionType = reflect.TypeOf(game.Fraction{})
+)
+
+// of returns the schema of a type as encoded by encoding/json.
+func (s *schemaSet) of(t reflect.Type) map[string]interface{} {
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t == numberType:
+		// Numbers also decode from JSON numbers, but are always sent as strings.
+		return map[string]interface{}{"type": "string", "pattern": "^[0-9]+$"}
+	case t == fractionType:
+		return map[string]interface{}{"type": "string", "pattern": `^[0-9]*([./][0-9]*)?$`}
+	case t.Kind() == reflect.Ptr:
+		schema := s.of(t.Elem())
+		if _, ref := schema["$ref"]; ref {
+			return map[string]interface{}{"allOf": []interface{}{schema}, "nullable": true}
+		}
+		schema["nullable"] = true
+		return schema
+	case t.Kind() == reflect.Struct && t.Name() != "":
+		name := schemaName(t)
+		if _, ok := s.defs[name]; !ok {
+			s.defs[name] = nil // reserve the name for recursive types
+			s.defs[name] = s.object(t)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+	case t.Kind() == reflect.Struct:
+		return s.object(t)
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": s.of(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": s.of(t.Elem())}
+	}
+	return map[string]interface{}{}
+}
+
+// object returns the schema of a struct, with embedded structs flattened as
+// encoding/json does.
+func (s *schemaSet) object(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	var required []string
+	s.fields(t, properties, &required)
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func (s *schemaSet) fields(t reflect.Type, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			s.fields(field.Type, properties, required)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = s.of(field.Type)
+		if !strings.Contains(opts, "omitempty") {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// schemaName names a struct's component after its package, except for the
+// types of this server.
+func schemaName(t reflect.Type) string {
+	pkg := t.PkgPath()
+	if pkg == "main" {
+		return t.Name()
+	}
+	return pkg[strings.LastIndex(pkg, "/")+1:] + "." + t.Name()
+}
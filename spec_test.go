@@ -0,0 +1,263 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	mismoclient "mismo/client"
+	"mismo/game"
+)
+
+// specDocument round-trips a generated document through JSON, as served.
+func specDocument(t *testing.T, doc map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("encoding document: %v", err)
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("decoding document: %v", err)
+	}
+	return out
+}
+
+// fillPath replaces the {name} wildcards of a route with sample values.
+func fillPath(path string) string {
+	return pathParams.ReplaceAllString(path, "ABC123")
+}
+
+func TestOpenAPICoversRoutes(t *testing.T) {
+	mux := http.NewServeMux()
+	registerAPI(mux)
+	paths := specDocument(t, openAPI())["paths"].(map[string]interface{})
+
+	documented := 0
+	for _, route := range apiRoutes() {
+		name := route.Method + " " + route.Path
+		if route.handler == nil {
+			t.Errorf("%s has no handler", name)
+		}
+		req := httptest.NewRequest(route.Method, fillPath(route.Path), nil)
+		if _, pattern := mux.Handler(req); pattern != name {
+			t.Errorf("%s is served by %q", name, pattern)
+		}
+
+		path, _ := paths[route.Path].(map[string]interface{})
+		operation, _ := path[strings.ToLower(route.Method)].(map[string]interface{})
+		if operation == nil {
+			t.Errorf("%s is missing from the OpenAPI document", name)
+			continue
+		}
+		documented++
+		responses, _ := operation["responses"].(map[string]interface{})
+		if _, ok := responses[strconv.Itoa(route.Status)]; !ok {
+			t.Errorf("%s does not document its %d response", name, route.Status)
+		}
+	}
+
+	operations := 0
+	for _, path := range paths {
+		operations += len(path.(map[string]interface{}))
+	}
+	if operations != documented {
+		t.Errorf("OpenAPI document has %d operations, want %d", operations, documented)
+	}
+}
+
+func TestAsyncAPICoversMessages(t *testing.T) {
+	mux := http.NewServeMux()
+	registerChannels(mux)
+	channels := specDocument(t, asyncAPI())["channels"].(map[string]interface{})
+
+	for _, channel := range wsChannels() {
+		req := httptest.NewRequest(http.MethodGet, fillPath(channel.Path), nil)
+		if _, pattern := mux.Handler(req); pattern != "GET "+channel.Path {
+			t.Errorf("%s is served by %q", channel.Path, pattern)
+		}
+
+		item, _ := channels[channel.Path].(map[string]interface{})
+		if item == nil {
+			t.Errorf("%s is missing from the AsyncAPI document", channel.Path)
+			continue
+		}
+		checkMessages(t, channel.Path+" publish", item["publish"], channel.Publish)
+		checkMessages(t, channel.Path+" subscribe", item["subscribe"], channel.Subscribe)
+	}
+	if len(channels) != len(wsChannels()) {
+		t.Errorf("AsyncAPI document has %d channels, want %d", len(channels), len(wsChannels()))
+	}
+}
+
+// checkMessages asserts that an operation documents exactly the messages of
+// a table.
+func checkMessages(t *testing.T, name string, operation interface{}, want []wsMessage) {
+	t.Helper()
+	documented := make(map[string]bool)
+	if op, ok := operation.(map[string]interface{}); ok {
+		message := op["message"].(map[string]interface{})
+		for _, m := range message["oneOf"].([]interface{}) {
+			documented[m.(map[string]interface{})["name"].(string)] = true
+		}
+	}
+	for _, m := range want {
+		typ := m.Type
+		if typ == "" {
+			typ = "error"
+		}
+		if !documented[typ] {
+			t.Errorf("%s: message %q is missing from the AsyncAPI document", name, typ)
+		}
+		delete(documented, typ)
+	}
+	for typ := range documented {
+		t.Errorf("%s: message %q is documented but not handled", name, typ)
+	}
+}
+
+func TestClientMessagesHaveHandlers(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, m := range clientMessages {
+		if m.handle == nil {
+			t.Errorf("client message %q has no handler", m.Type)
+		}
+		if seen[m.Type] {
+			t.Errorf("client message %q is listed twice", m.Type)
+		}
+		seen[m.Type] = true
+	}
+}
+
+func TestSpecReferencesResolve(t *testing.T) {
+	for name, doc := range map[string]map[string]interface{}{"OpenAPI": openAPI(), "AsyncAPI": asyncAPI()} {
+		doc = specDocument(t, doc)
+		schemas := doc["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+		var walk func(v interface{})
+		walk = func(v interface{}) {
+			switch v := v.(type) {
+			case map[string]interface{}:
+				if ref, ok := v["$ref"].(string); ok {
+					if _, ok := schemas[strings.TrimPrefix(ref, "#/components/schemas/")]; !ok {
+						t.Errorf("%s: unresolved reference %s", name, ref)
+					}
+				}
+				for _, child := range v {
+					walk(child)
+				}
+			case []interface{}:
+				for _, child := range v {
+					walk(child)
+				}
+			}
+		}
+		walk(doc)
+	}
+}
+
+// jsonFields lists the JSON properties of a type, marking required ones.
+func jsonFields(t reflect.Type) []string {
+	schema := newSchemaSet().object(t)
+	required := make(map[string]bool)
+	if list, ok := schema["required"].([]string); ok {
+		for _, name := range list {
+			required[name] = true
+		}
+	}
+	var fields []string
+	for name := range schema["properties"].(map[string]interface{}) {
+		if name == "type" {
+			continue // the message type, which clients switch on first
+		}
+		if !required[name] {
+			name += ",omitempty"
+		}
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func TestClientTypesMatchServer(t *testing.T) {
+	tests := []struct {
+		name           string
+		server, client interface{}
+	}{
+		{"state", stateMessage{}, mismoclient.State{}},
+		{"player", playerView{}, mismoclient.Player{}},
+		{"options", Options{}, mismoclient.Options{}},
+		{"chat", chatMessage{}, mismoclient.ChatMessage{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sent, decoded := jsonFields(reflect.TypeOf(tt.server)), jsonFields(reflect.TypeOf(tt.client))
+			if !reflect.DeepEqual(sent, decoded) {
+				t.Errorf("server sends %v, client decodes %v", sent, decoded)
+			}
+		})
+	}
+}
+
+// roundTrip decodes a server message into a client type and checks that it
+// encodes back to the same JSON, less the message type.
+func roundTrip(t *testing.T, message, decoded interface{}) {
+	t.Helper()
+	data, err := json.Marshal(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want map[string]interface{}
+	json.Unmarshal(data, &want)
+	delete(want, "type")
+
+	if err := json.Unmarshal(data, decoded); err != nil {
+		t.Fatalf("client cannot decode %s: %v", data, err)
+	}
+	again, _ := json.Marshal(decoded)
+	var got map[string]interface{}
+	json.Unmarshal(again, &got)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip changed the message:\nserver %s\nclient %s", data, again)
+	}
+}
+
+func TestClientDecodesServerMessages(t *testing.T) {
+	opts := defaultOptions()
+	opts.MinPlayers = 2
+	opts.CardEvery = 1
+	opts.RoundSeconds = 60
+	opts.Teams = 2
+	g := testGame(t, opts)
+	ids := make([]string, 4)
+	for i, name := range []string{"Ana", "Bea", "Cy", "Dan"} {
+		id, err := g.addPlayer(name, "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[i] = id
+	}
+	if err := g.engine.Start(ids[0]); err != nil {
+		t.Fatal(err)
+	}
+	for i, id := range ids {
+		if err := g.engine.SubmitNumber(id, game.NewNumber(uint64(i*10))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	state := g.state(ids[0])
+	if state.LastRound == nil || len(state.Players[ids[0]].Cards) == 0 {
+		t.Fatalf("state is not fully populated: %+v", state)
+	}
+	state.Standings = game.NewStandings(g.engine.Snapshot().Players)
+	deadline := time.Now().Truncate(time.Second)
+	state.Deadline = &deadline
+
+	roundTrip(t, state, &mismoclient.State{})
+	roundTrip(t, chatMessage{Type: "chat", PlayerID: ids[0], Name: "Ana", Team: 1, Text: "hi", SentAt: deadline}, &mismoclient.ChatMessage{})
+}
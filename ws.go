@@ -0,0 +1,413 @@
+// ws.go
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"mismo/game"
+	"mismo/ratelimit"
+
+	"github.com/gorilla/websocket"
+)
+
+// Messages sent by players over /ws/game/{id}. Every message has a "type"
+// naming it in clientMessages.
+
+type joinMessage struct {
+	Type     string `json:"type"`
+	Name     string `json:"name,omitempty"`
+	Password string `json:"password,omitempty"`
+	Invite   string `json:"invite,omitempty"`
+	// Account is a token from POST /api/v1/accounts; the game is rated
+	// under it.
+	Account string `json:"account,omitempty"`
+	// Token attaches the connection to a player who joined over REST.
+	Token string `json:"token,omitempty"`
+}
+
+type startMessage struct {
+	Type string `json:"type"`
+}
+
+type numberMessage struct {
+	Type string `json:"type"`
+	// Number is a decimal string; JSON numbers are accepted too but lose
+	// precision in most clients beyond 2^53.
+	Number *game.Number `json:"number"`
+}
+
+type lockMessage struct {
+	Type string `json:"type"`
+}
+
+type teamMessage struct {
+	Type     string `json:"type"`
+	PlayerID string `json:"playerId"`
+	Team     int    `json:"team"`
+}
+
+type balanceTeamsMessage struct {
+	Type string `json:"type"`
+}
+
+type cardMessage struct {
+	Type string    `json:"type"`
+	Card game.Card `json:"card"`
+	// Target is the player a peek or swap is aimed at.
+	Target string `json:"target,omitempty"`
+}
+
+type wagerMessage struct {
+	Type string `json:"type"`
+}
+
+type nextRoundMessage struct {
+	Type string `json:"type"`
+}
+
+type inviteRequestMessage struct {
+	Type string `json:"type"`
+}
+
+// Messages sent to players besides stateMessage.
+
+type joinedMessage struct {
+	Type     string `json:"type"`
+	PlayerID string `json:"playerId"`
+	Token    string `json:"token,omitempty"`
+}
+
+type inviteMessage struct {
+	Type      string    `json:"type"`
+	Invite    string    `json:"invite"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+type errorMessage struct {
+	Error string `json:"error"`
+}
+
+// wsMessage describes a message type of a WebSocket channel. Messages sent
+// by clients have a handler; the AsyncAPI document is generated from the
+// same tables, so it lists exactly what the server handles.
+type wsMessage struct {
+	Type    string
+	Summary string
+	Payload interface{}
+	handle  func(s *wsSession, data []byte)
+}
+
+// clientMessages are the messages players can send over /ws/game/{id}.
+var clientMessages = []wsMessage{
+	{"join", "Join the game, or attach to a player who joined over REST.", joinMessage{}, (*wsSession).join},
+	{"start", "Start the game (host only).", startMessage{}, (*wsSession).start},
+	{"number", "Submit or change a number for the current round.", numberMessage{}, (*wsSession).number},
+	{"lock", "Lock in the submitted number.", lockMessage{}, (*wsSession).lock},
+	{"card", "Play a card from your hand this round.", cardMessage{}, (*wsSession).card},
+	{"wager", "Stake a life on not losing one this round.", wagerMessage{}, (*wsSession).wager},
+	{"nextRound", "Start the next round (host only).", nextRoundMessage{}, (*wsSession).nextRound},
+	{"invite", "Create an invite for a private game (host only).", inviteRequestMessage{}, (*wsSession).invite},
+	{"team", "Move a player to a team before the game starts (host only).", teamMessage{}, (*wsSession).team},
+	{"balanceTeams", "Deal the players out evenly to the teams (host only).", balanceTeamsMessage{}, (*wsSession).balanceTeams},
+	{"chat", "Send a message to your team.", chatRequestMessage{}, (*wsSession).chat},
+}
+
+// serverMessages are the messages sent to players over /ws/game/{id}.
+// Errors carry no type.
+var serverMessages = []wsMessage{
+	{Type: "state", Summary: "The game state, sent after every change.", Payload: stateMessage{}},
+	{Type: "joined", Summary: "Confirms a join with the player's ID and REST token.", Payload: joinedMessage{}},
+	{Type: "invite", Summary: "An invite created by the host.", Payload: inviteMessage{}},
+	{Type: "chat", Summary: "A message from a teammate.", Payload: chatMessage{}},
+	{Summary: "A rejected message.", Payload: errorMessage{}},
+}
+
+// wsChannel is a WebSocket endpoint. Publish lists the messages clients
+// send and Subscribe those they receive.
+type wsChannel struct {
+	Path      string
+	Summary   string
+	Publish   []wsMessage
+	Subscribe []wsMessage
+	handler   http.HandlerFunc
+}
+
+// wsChannels lists the WebSocket endpoints; they are registered from this
+// table and the AsyncAPI document is generated from it.
+func wsChannels() []wsChannel {
+	return []wsChannel{
+		{"/ws/game/{id}", "Play a game.", clientMessages, serverMessages, wsHandler},
+		{"/ws/lobbies", "Follow the list of public games.", nil, lobbyMessages, lobbiesWsHandler},
+		{"/ws/matchmaking", "Wait to be matched into a new game.", matchmakingMessages, matchedMessages, matchmakingHandler},
+	}
+}
+
+// registerChannels adds the WebSocket endpoints to mux.
+func registerChannels(mux *http.ServeMux) {
+	for _, channel := range wsChannels() {
+		mux.HandleFunc("GET "+channel.Path, channel.handler)
+	}
+}
+
+// wsSession is a connection to /ws/game/{id}.
+type wsSession struct {
+	g *Game
+	c *client
+	r *http.Request
+	// playerID is set once the connection has joined; joinedHere is false
+	// for players who joined through the REST API and only attached.
+	playerID   string
+	joinedHere bool
+}
+
+// wsHandler manages WebSocket connections for a specific game.
+func wsHandler(w http.ResponseWriter, r *http.Request) {
+	g, exists := findGame(r.PathValue("id"))
+	if !exists {
+		http.Error(w, "Game not found.", http.StatusNotFound)
+		return
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("WebSocket Upgrade Error: %v", err)
+		return
+	}
+	defer conn.Close()
+	conn.SetReadLimit(maxMessageSize)
+
+	s := &wsSession{g: g, c: &client{conn: conn}, r: r}
+	messages := ratelimit.NewBucket(messageRate, messageBurst)
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			log.Printf("WebSocket Read Error: %v", err)
+			s.disconnect()
+			break
+		}
+		if !messages.Allow() {
+			closeMessage := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "Too many messages.")
+			s.c.mu.Lock()
+			conn.WriteControl(websocket.CloseMessage, closeMessage, time.Now().Add(time.Second))
+			s.c.mu.Unlock()
+			s.disconnect()
+			break
+		}
+
+		var msg struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(data, &msg); err != nil {
+			s.c.sendError("Invalid message.")
+			continue
+		}
+		if msg.Type != "join" && s.playerID == "" {
+			s.c.sendError("Join the game first.")
+			continue
+		}
+		s.dispatch(msg.Type, data)
+	}
+}
+
+// dispatch passes a message to the handler for its type.
+func (s *wsSession) dispatch(messageType string, data []byte) {
+	for _, m := range clientMessages {
+		if m.Type == messageType {
+			m.handle(s, data)
+			return
+		}
+	}
+	s.c.sendError("Unknown message type.")
+}
+
+// decode reads a message into v, reporting malformed messages to the client.
+func (s *wsSession) decode(data []byte, v interface{}) bool {
+	err := json.Unmarshal(data, v)
+	if errors.Is(err, game.ErrInvalidNumber) || errors.Is(err, game.ErrNumberOutOfRange) {
+		s.c.sendError("Invalid number: " + err.Error() + ".")
+		return false
+	}
+	if err != nil {
+		s.c.sendError("Invalid message.")
+		return false
+	}
+	return true
+}
+
+// disconnect removes a player who joined over this connection, or detaches
+// one who joined over REST.
+func (s *wsSession) disconnect() {
+	if s.joinedHere {
+		s.g.leave(s.playerID)
+	} else if s.playerID != "" {
+		s.g.detach(s.playerID, s.c)
+	}
+}
+
+func (s *wsSession) join(data []byte) {
+	var msg joinMessage
+	if !s.decode(data, &msg) {
+		return
+	}
+	if s.playerID != "" {
+		s.c.sendError("Already joined.")
+		return
+	}
+
+	if msg.Token != "" {
+		// Players who joined through the REST API attach with their token.
+		claims, err := signer.VerifyPlayerToken(msg.Token)
+		if err != nil || claims.GameID != s.g.ID || !s.g.hasPlayer(claims.PlayerID) {
+			s.c.sendError("Invalid token.")
+			return
+		}
+		s.playerID = claims.PlayerID
+		s.g.attach(s.playerID, s.c)
+		s.c.send(joinedMessage{Type: "joined", PlayerID: s.playerID})
+		s.g.broadcast()
+		return
+	}
+
+	if !joinLimiter.Allow(ratelimit.ClientIP(s.r)) {
+		s.c.sendError("Too many join attempts, try again later.")
+		return
+	}
+	if msg.Name == "" {
+		s.c.sendError("Invalid name.")
+		return
+	}
+	if err := s.g.checkAccess(msg.Password, msg.Invite); err != nil {
+		s.c.sendError(err.Error())
+		return
+	}
+	account, err := verifyAccount(msg.Account)
+	if err != nil {
+		s.c.sendError(err.Error())
+		return
+	}
+	playerID, err := s.g.addPlayer(msg.Name, account, s.c)
+	if isNameError(err) {
+		s.c.sendError("Invalid name: " + err.Error() + ".")
+		return
+	}
+	if err != nil {
+		s.c.sendError(err.Error())
+		return
+	}
+	s.playerID, s.joinedHere = playerID, true
+
+	token, err := signer.NewPlayerToken(s.g.ID, playerID, tokenTTL)
+	if err != nil {
+		log.Printf("Error creating player token: %v", err)
+	}
+	s.c.send(joinedMessage{Type: "joined", PlayerID: playerID, Token: token})
+	s.g.broadcast()
+}
+
+func (s *wsSession) start(data []byte) {
+	if err := s.g.engine.Start(s.playerID); err != nil {
+		s.c.sendError(err.Error())
+		return
+	}
+	s.g.broadcast()
+}
+
+func (s *wsSession) number(data []byte) {
+	var msg numberMessage
+	if !s.decode(data, &msg) {
+		return
+	}
+	if msg.Number == nil {
+		s.c.sendError("Invalid number.")
+		return
+	}
+	if err := s.g.engine.SubmitNumber(s.playerID, *msg.Number); err != nil {
+		s.c.sendError(err.Error())
+		return
+	}
+	s.g.broadcast()
+}
+
+func (s *wsSession) lock(data []byte) {
+	if err := s.g.engine.LockNumber(s.playerID); err != nil {
+		s.c.sendError(err.Error())
+		return
+	}
+	s.g.broadcast()
+}
+
+func (s *wsSession) card(data []byte) {
+	var msg cardMessage
+	if !s.decode(data, &msg) {
+		return
+	}
+	if err := s.g.engine.PlayCard(s.playerID, msg.Card, msg.Target); err != nil {
+		s.c.sendError(err.Error())
+		return
+	}
+	s.g.broadcast()
+}
+
+func (s *wsSession) wager(data []byte) {
+	if err := s.g.engine.PlaceWager(s.playerID); err != nil {
+		s.c.sendError(err.Error())
+		return
+	}
+	s.g.broadcast()
+}
+
+func (s *wsSession) nextRound(data []byte) {
+	if err := s.g.engine.NextRound(s.playerID); err != nil {
+		s.c.sendError(err.Error())
+		return
+	}
+	s.g.broadcast()
+}
+
+func (s *wsSession) team(data []byte) {
+	var msg teamMessage
+	if !s.decode(data, &msg) {
+		return
+	}
+	if err := s.g.engine.AssignTeam(s.playerID, msg.PlayerID, msg.Team); err != nil {
+		s.c.sendError(err.Error())
+		return
+	}
+	s.g.broadcast()
+}
+
+func (s *wsSession) balanceTeams(data []byte) {
+	if err := s.g.engine.BalanceTeams(s.playerID); err != nil {
+		s.c.sendError(err.Error())
+		return
+	}
+	s.g.broadcast()
+}
+
+func (s *wsSession) chat(data []byte) {
+	var msg chatRequestMessage
+	if !s.decode(data, &msg) {
+		return
+	}
+	if err := s.g.chat(s.playerID, msg.Text); err != nil {
+		s.c.sendError(err.Error())
+	}
+}
+
+func (s *wsSession) invite(data []byte) {
+	if !s.g.isHost(s.playerID) {
+		s.c.sendError("Only host can create invites.")
+		return
+	}
+	invite, expires, err := signer.NewInvite(s.g.ID, inviteTTL)
+	if err != nil {
+		s.c.sendError("Failed to create invite.")
+		return
+	}
+	s.c.send(inviteMessage{Type: "invite", Invite: invite, ExpiresAt: expires})
+}